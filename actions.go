@@ -0,0 +1,214 @@
+package goapplib
+
+import (
+	"errors"
+	"maps"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Poster is implemented by views that handle POST requests.
+// Like View.Load, it returns (error, finished); see serveAction for how the
+// result is turned into a response.
+type Poster[AC any] interface {
+	Post(r *http.Request, w http.ResponseWriter, app *App[AC]) (err error, finished bool)
+}
+
+// Putter is implemented by views that handle PUT requests.
+type Putter[AC any] interface {
+	Put(r *http.Request, w http.ResponseWriter, app *App[AC]) (err error, finished bool)
+}
+
+// Patcher is implemented by views that handle PATCH requests.
+type Patcher[AC any] interface {
+	Patch(r *http.Request, w http.ResponseWriter, app *App[AC]) (err error, finished bool)
+}
+
+// Deleter is implemented by views that handle DELETE requests.
+type Deleter[AC any] interface {
+	Delete(r *http.Request, w http.ResponseWriter, app *App[AC]) (err error, finished bool)
+}
+
+// SuccessRedirector is optionally implemented by views with actions to choose
+// where the browser goes after a successful action. Defaults to the request URL.
+type SuccessRedirector interface {
+	SuccessURL(r *http.Request) string
+}
+
+// ValidationError reports invalid input from an action. Returning it makes
+// Register re-render the same view (with status 422) instead of redirecting,
+// so the template can show Fields next to the inputs. Fields are copied into
+// the view's Errors map[string]string field, if it has one.
+type ValidationError struct {
+	Fields map[string]string // Field name -> error message
+}
+
+func (e *ValidationError) Error() string {
+	var msgs []string
+	for field, msg := range e.Fields {
+		msgs = append(msgs, field+": "+msg)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// StatusCode implements StatusCoder.
+func (e *ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// MethodOverride lets plain HTML forms send PUT, PATCH and DELETE requests
+// through a POST with a "_method" form field. Use it around your root mux so
+// method-specific patterns ("DELETE /games/{id}") match. Views registered with
+// Register apply the override themselves if they have action methods (Poster,
+// Putter, Patcher or Deleter); other views only see it through this
+// middleware. Only urlencoded and multipart form POSTs are overridden.
+func MethodOverride(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		applyMethodOverride(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// applyMethodOverride rewrites r.Method from the _method form field of a
+// POST. Only form bodies are read, so other POSTs keep their body intact.
+func applyMethodOverride(r *http.Request) {
+	if r.Method != http.MethodPost {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" && mediaType != "multipart/form-data" {
+		return
+	}
+	switch method := strings.ToUpper(r.PostFormValue("_method")); method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		r.Method = method
+	}
+}
+
+// actionFor returns the action handler of view for the request method.
+// hasActions is false if the view implements no action interface at all,
+// in which case every method is served by Load as before.
+func actionFor[AC any](view View[AC], method string) (action func(*http.Request, http.ResponseWriter, *App[AC]) (error, bool), hasActions bool) {
+	poster, isPoster := view.(Poster[AC])
+	putter, isPutter := view.(Putter[AC])
+	patcher, isPatcher := view.(Patcher[AC])
+	deleter, isDeleter := view.(Deleter[AC])
+	hasActions = isPoster || isPutter || isPatcher || isDeleter
+
+	switch {
+	case method == http.MethodPost && isPoster:
+		action = poster.Post
+	case method == http.MethodPut && isPutter:
+		action = putter.Put
+	case method == http.MethodPatch && isPatcher:
+		action = patcher.Patch
+	case method == http.MethodDelete && isDeleter:
+		action = deleter.Delete
+	}
+	return
+}
+
+// allowedMethods lists the methods a view with actions accepts.
+func allowedMethods[AC any](view View[AC]) []string {
+	methods := []string{http.MethodGet, http.MethodHead}
+	if _, ok := view.(Poster[AC]); ok {
+		methods = append(methods, http.MethodPost)
+	}
+	if _, ok := view.(Putter[AC]); ok {
+		methods = append(methods, http.MethodPut)
+	}
+	if _, ok := view.(Patcher[AC]); ok {
+		methods = append(methods, http.MethodPatch)
+	}
+	if _, ok := view.(Deleter[AC]); ok {
+		methods = append(methods, http.MethodDelete)
+	}
+	return methods
+}
+
+// runAction runs a view's action after Load. It returns rerender=true when
+// the view should be rendered again (validation failed); otherwise the
+// response has been written (redirect, error, or by the action itself).
+// Redirects go through Redirect, so HTMX requests get HX-Redirect.
+func runAction[AC any](app *App[AC], view View[AC], action func(*http.Request, http.ResponseWriter, *App[AC]) (error, bool), w http.ResponseWriter, r *http.Request) (rerender bool) {
+	err, finished := action(r, w, app)
+	if finished {
+		return false
+	}
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			failRequest(r, err)
+			setViewErrors(view, validationErr.Fields)
+			return true
+		}
+		app.HandleError(w, r, err)
+		return false
+	}
+
+	// Post/Redirect/Get
+	target := r.URL.RequestURI()
+	if sr, ok := view.(SuccessRedirector); ok {
+		if u := sr.SuccessURL(r); u != "" {
+			target = u
+		}
+	}
+	RedirectTo(target).ServeHTTP(w, r)
+	return false
+}
+
+// setViewErrors adds fields to the Errors map[string]string field of view,
+// if it is a pointer to a struct with one, the way DecodeForm fills it.
+// Errors already set by the action are kept.
+func setViewErrors(view any, fields map[string]string) {
+	v := reflect.ValueOf(view)
+	if len(fields) == 0 || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	field := errorsField(v.Elem())
+	if !field.IsValid() {
+		return
+	}
+	if field.IsNil() {
+		field.Set(reflect.ValueOf(maps.Clone(fields)))
+		return
+	}
+	for name, msg := range fields {
+		if !field.MapIndex(reflect.ValueOf(name)).IsValid() {
+			field.SetMapIndex(reflect.ValueOf(name), reflect.ValueOf(msg))
+		}
+	}
+}
+
+// statusWriter replaces the implicit 200 status of a render with status.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if code == http.StatusOK {
+		code = sw.status
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// overridesStatus reports whether w is, or wraps, a statusWriter.
+func overridesStatus(w http.ResponseWriter) bool {
+	for {
+		switch x := w.(type) {
+		case *statusWriter:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = x.Unwrap()
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package goapplib
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// rawBodyPage reads the request body itself and has no actions.
+type rawBodyPage struct{}
+
+func (p *rawBodyPage) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	body, _ := io.ReadAll(r.Body)
+	w.Write(body)
+	return nil, true
+}
+
+// deletablePage has a Delete action reached through _method.
+type deletablePage struct{}
+
+func (p *deletablePage) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	return nil, false
+}
+
+func (p *deletablePage) Delete(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	w.Write([]byte("deleted"))
+	return nil, true
+}
+
+func postForm(handler http.Handler, target, contentType, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// TestMethodOverrideKeepsBodyWithoutActions checks that views without
+// actions can still read the POST body.
+func TestMethodOverrideKeepsBodyWithoutActions(t *testing.T) {
+	mux := Register[*rawBodyPage](NewApp(struct{}{}, nil), nil, "/raw")
+	w := postForm(mux, "/raw", "application/x-www-form-urlencoded", "a=1&b=2")
+	if got := w.Body.String(); got != "a=1&b=2" {
+		t.Fatalf("Load read %q, want the raw body", got)
+	}
+}
+
+// TestMethodOverride checks that _method reaches the action of a form POST,
+// and only of a form POST.
+func TestMethodOverride(t *testing.T) {
+	mux := Register[*deletablePage](NewApp(struct{}{}, nil), nil, "/item")
+	tests := []struct {
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"application/x-www-form-urlencoded", "_method=DELETE", http.StatusOK},
+		{"application/x-www-form-urlencoded; charset=utf-8", "_method=delete", http.StatusOK},
+		{"application/json", `{"_method":"DELETE"}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		w := postForm(mux, "/item", tt.contentType, tt.body)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s %q: got %d, want %d", tt.contentType, tt.body, w.Code, tt.wantStatus)
+		}
+	}
+}
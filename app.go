@@ -1,13 +1,17 @@
 package goapplib
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"log"
 	"net/http"
 	"reflect"
 	"strings"
+	"sync"
 
 	tmplr "github.com/panyam/templar"
 )
@@ -18,6 +22,80 @@ type App[AppContext any] struct {
 	Context            AppContext
 	Templates          *tmplr.TemplateGroup
 	RenderTemplateFunc func(w http.ResponseWriter, templateFileName string, templateBlockName string, view any) error
+
+	// ErrorPage is an optional template spec (same format as WithTemplate) used
+	// to render error responses. It is rendered with an *ErrorPageData.
+	// If empty, or if it fails to render, a plain text error is written.
+	ErrorPage string
+
+	// ErrorFragment is an optional template spec rendered instead of ErrorPage
+	// for HTMX requests. htmx does not swap 4xx/5xx responses, so it is sent
+	// with status 200 (ErrorPageData.Status has the real one). If empty, or
+	// if it fails to render, HTMX requests get an empty body and a
+	// "showToast" HX-Trigger that the Toast component displays.
+	ErrorFragment string
+
+	// ErrorTarget is an optional CSS selector ErrorFragment is swapped into
+	// (e.g., "#errors"), with HX-Retarget and HX-Reswap. If empty, the
+	// fragment replaces the target of the request like a normal response.
+	ErrorTarget string
+
+	// RequestFuncs, if set, returns extra template funcs for a single request
+	// (e.g., csrfToken, currentUser, T). They are bound only for renders of
+	// that request, on top of DefaultFuncMap and the built-in RequestFuncMap,
+	// and win over funcs of the same name from either.
+	RequestFuncs func(r *http.Request, view any) template.FuncMap
+
+	// TimeoutPage is an optional template spec rendered (with an
+	// *ErrorPageData) when a view's load deadline passes (see WithTimeout).
+	// If empty, the error page is used.
+	TimeoutPage string
+
+	// EnableJSON lets views registered with Register or SmartRegister respond
+	// with JSON when the request asks for it (see WantsJSON and RenderJSON).
+	// Can be overridden per route with WithJSON.
+	EnableJSON bool
+
+	// Cache, if set, stores the rendered output of views implementing
+	// CacheableView. NewLRUCache provides an in-memory implementation.
+	Cache FragmentCache
+
+	// NewRequestContext, if set, creates a request-scoped context (current
+	// user, DB transaction, tenant, logger, ...) once per request served by
+	// Register. Views reach it with RequestContext[T](r). If it implements
+	// RequestCloser or io.Closer, it is closed after the response is written.
+	// An error fails the request before Load runs.
+	NewRequestContext func(r *http.Request) (any, error)
+
+	// Flashes stores flash messages between requests (see AddFlash). If nil,
+	// a cookie store with a random per-process key is used, so pending
+	// flashes do not survive a restart or move between instances; set a
+	// NewCookieFlashStore with a fixed secret for that.
+	Flashes FlashStore
+
+	// DevMode shows detailed template errors (file, line) in the browser
+	// instead of a generic error page. Enabled by EnableLocalDev.
+	DevMode bool
+
+	// Reloader, if set, supplies the TemplateGroup used for rendering in place
+	// of Templates and rebuilds it when template files change.
+	Reloader *TemplateReloader
+
+	routesMu   sync.Mutex
+	routes     []RouteInfo
+	routeNames map[string]int // Index into routes by RouteInfo.Name
+
+	// Groups currently being registered, outermost first (see RegisterGroup)
+	groups []groupFrame
+
+	mount  *mountPoint  // Set if the app is mounted in another (see Mount)
+	mounts []mountedApp // Apps mounted in this one
+}
+
+// groupFrame is a group being registered by RegisterGroup.
+type groupFrame struct {
+	prefix string
+	opts   []Option
 }
 
 // NewApp creates a new App with the given application context and templates.
@@ -30,37 +108,124 @@ func NewApp[AppContext any](ctx AppContext, templates *tmplr.TemplateGroup) *App
 
 // RenderTemplate renders the named template with the given view data.
 // If RenderTemplateFunc is set, it delegates to that function.
+//
+// The template is rendered into a pooled buffer first, so nothing is written
+// to w if rendering fails and callers can still send a clean error response.
+// On success the body is written with a Content-Length and the status code
+// chosen by the view (see StatusCoder), defaulting to 200.
 func (app *App[AppContext]) RenderTemplate(
 	w http.ResponseWriter,
 	templateFileName string,
 	templateBlockName string,
 	view any) error {
+	return app.RenderRequest(w, nil, templateFileName, templateBlockName, view)
+}
+
+// RenderRequest is like RenderTemplate but also binds the request-scoped
+// template funcs (see App.RequestFuncs) for this render.
+func (app *App[AppContext]) RenderRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	templateFileName string,
+	templateBlockName string,
+	view any) error {
 	if app.RenderTemplateFunc != nil {
 		return app.RenderTemplateFunc(w, templateFileName, templateBlockName, view)
 	}
 
-	// Default implementation using Templates
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := app.renderTo(buf, r, templateFileName, templateBlockName, view); err != nil {
+		return err
+	}
+	return writeBuffered(w, statusCodeOf(view), buf)
+}
+
+// renderTo renders the named template into out using the Templates group.
+// r may be nil when rendering outside of a request.
+func (app *App[AppContext]) renderTo(out io.Writer, r *http.Request, templateFileName string, templateBlockName string, view any) error {
+	root, err := app.loadRoot(templateFileName)
+	if err != nil {
+		return err
+	}
+	return app.renderRoot(out, r, root, templateFileName, templateBlockName, view)
+}
+
+// loadRoot loads the root template for a template file name (without extension).
+func (app *App[AppContext]) loadRoot(templateFileName string) (*tmplr.Template, error) {
 	templateFile := templateFileName + ".html"
-	tmpl, err := app.Templates.Loader.Load(templateFile, "")
+	tmpl, err := app.templateGroup().Loader.Load(templateFile, "")
 	if err != nil {
 		log.Printf("Template load error: %s - %v", templateFile, err)
-		return fmt.Errorf("template load error: %s - %w", templateFile, err)
+		return nil, fmt.Errorf("template load error: %s - %w", templateFile, err)
 	}
+	return tmpl[0], nil
+}
+
+// renderRoot renders the block of an already loaded root template into out.
+// label identifies the template in errors and logs.
+func (app *App[AppContext]) renderRoot(out io.Writer, r *http.Request, root *tmplr.Template, label string, templateBlockName string, view any) (err error) {
+	// A broken template must not take the server down
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Template render panic: %s[%s] - %v", label, templateBlockName, p)
+			err = fmt.Errorf("template render panic: %s[%s] - %v", label, templateBlockName, p)
+		}
+	}()
 
-	err = app.Templates.RenderHtmlTemplate(w, tmpl[0], templateBlockName, view, nil)
+	err = app.templateGroup().RenderHtmlTemplate(out, root, templateBlockName, view, app.requestFuncs(r, view))
 	if err != nil {
-		log.Printf("Template render error: %s[%s] - %v", templateFileName, templateBlockName, err)
-		return fmt.Errorf("template render error: %s[%s] - %w", templateFileName, templateBlockName, err)
+		log.Printf("Template render error: %s[%s] - %v", label, templateBlockName, err)
+		return fmt.Errorf("template render error: %s[%s] - %w", label, templateBlockName, err)
 	}
-
 	return nil
 }
 
+// EnableLocalDev turns on DevMode and hot-reloading of templates until ctx is
+// done. Changes to .html files in the given directories rebuild the app's
+// Templates with the same loader and funcs. WebAppServer calls it when
+// AllowLocalDev is set and its App is this app (see WebAppServer.App).
+// Without paths nothing is watched (e.g., templates only loaded from an
+// fs.FS), so a warning naming the app's template loaders is logged.
+func (app *App[AppContext]) EnableLocalDev(ctx context.Context, paths ...string) *TemplateReloader {
+	app.DevMode = true
+	if len(paths) == 0 {
+		log.Printf("Warning: no template paths to watch, templates loaded by %s will not be reloaded (set WebAppServer.TemplatePaths)",
+			strings.Join(templateLoaderNames(app.Templates), ", "))
+	}
+	app.Reloader = NewTemplateReloader(paths...)
+	app.Reloader.Base = app.Templates
+	app.Reloader.Start(ctx)
+	return app.Reloader
+}
+
+// templateGroup returns the TemplateGroup to render with. A mounted app
+// (see Mount) looks up templates in its own group first and then in its
+// parent's, or only in its parent's if it has none.
+func (app *App[AppContext]) templateGroup() *tmplr.TemplateGroup {
+	own := app.Templates
+	if app.Reloader != nil {
+		own = app.Reloader.Templates()
+	}
+	if app.mount == nil {
+		return own
+	}
+	parent := app.mount.parent.templateGroup()
+	if own == nil || own.Loader == nil {
+		return parent
+	}
+	if parent == nil || parent.Loader == nil || parent == own {
+		return own
+	}
+	return app.mount.chainTemplates(own, parent)
+}
+
 // NewMux creates a MuxBuilder for fluent route building.
 func (app *App[AppContext]) NewMux() *MuxBuilder[AppContext] {
 	return &MuxBuilder[AppContext]{
-		app: app,
-		mux: http.NewServeMux(),
+		app:    app,
+		mux:    http.NewServeMux(),
+		prefix: app.groupPrefix(),
 	}
 }
 
@@ -81,6 +246,25 @@ func SetupTemplates(paths ...string) *tmplr.TemplateGroup {
 	return templates
 }
 
+// SetupTemplatesFS creates a TemplateGroup that loads templates from the given
+// file systems (e.g., embed.FS, os.DirFS). File systems are checked in order,
+// so put your app's templates first and goapplib's TemplatesFS last to layer
+// your overrides on top of the library defaults.
+func SetupTemplatesFS(fsys ...fs.FS) *tmplr.TemplateGroup {
+	templates := tmplr.NewTemplateGroup()
+
+	loader := &tmplr.LoaderList{}
+	for _, f := range fsys {
+		loader.AddLoader(NewFSLoader(f))
+	}
+	templates.Loader = loader
+
+	// Add default functions
+	templates.AddFuncs(DefaultFuncMap())
+
+	return templates
+}
+
 // DefaultFuncMap returns the default template functions.
 func DefaultFuncMap() template.FuncMap {
 	return template.FuncMap{
@@ -186,6 +370,12 @@ func DefaultFuncMap() template.FuncMap {
 			lines := strings.Split(strings.TrimSpace(code), "\n")
 			return strings.Join(lines, "<br/>")
 		},
+		// Request-scoped placeholders so templates parse outside a request;
+		// replaced per render by RequestFuncMap
+		"currentPath": currentPathPlaceholder,
+		"isActive":    isActivePlaceholder,
+		"flashes":     flashesPlaceholder,
+		"urlFor":      urlForPlaceholder,
 		// Generic ToJson (apps can override with protobuf-aware version)
 		"ToJson": func(v any) template.JS {
 			if v == nil {
@@ -201,6 +391,95 @@ func DefaultFuncMap() template.FuncMap {
 	}
 }
 
+// Placeholders for the request-scoped funcs in DefaultFuncMap. A group func
+// of the same name that is not its placeholder was added by the app.
+func currentPathPlaceholder() string         { return "" }
+func isActivePlaceholder(prefix string) bool { return false }
+func flashesPlaceholder() []Flash            { return nil }
+func urlForPlaceholder(name string, params ...any) (string, error) {
+	return "", fmt.Errorf("urlFor %q: not rendered by an App", name)
+}
+
+var requestFuncPlaceholders = map[string]any{
+	"currentPath": currentPathPlaceholder,
+	"isActive":    isActivePlaceholder,
+	"flashes":     flashesPlaceholder,
+	"urlFor":      urlForPlaceholder,
+}
+
+// isRequestFuncPlaceholder reports whether fn is DefaultFuncMap's placeholder
+// for the request-scoped func name.
+func isRequestFuncPlaceholder(name string, fn any) bool {
+	placeholder, ok := requestFuncPlaceholders[name]
+	if !ok {
+		return false
+	}
+	v := reflect.ValueOf(fn)
+	return v.Kind() == reflect.Func && v.Pointer() == reflect.ValueOf(placeholder).Pointer()
+}
+
+// RequestFuncMap returns the built-in template functions bound to a request:
+//   - currentPath: the request's URL path
+//   - isActive "/games": true if the path is "/games" or below it
+//   - flashes: the flash messages to show on this page (see AddFlash)
+//
+// Renders bind these and urlFor unless the app added its own func of the
+// same name to the template group.
+func RequestFuncMap(r *http.Request) template.FuncMap {
+	path := r.URL.Path
+	return template.FuncMap{
+		"currentPath": func() string {
+			return path
+		},
+		"isActive": func(prefix string) bool {
+			if prefix == "/" {
+				return path == "/"
+			}
+			prefix = strings.TrimSuffix(prefix, "/")
+			return path == prefix || strings.HasPrefix(path, prefix+"/")
+		},
+		"flashes": func() []Flash {
+			return Flashes(r)
+		},
+	}
+}
+
+// requestFuncs builds the per-render funcs: urlFor, and for a request (r is
+// not nil) RequestFuncMap and App.RequestFuncs.
+// A built-in func is not bound if the app added its own func of that name to
+// the template group (with AddFuncs), so the app's func wins. App.RequestFuncs
+// wins over both.
+// A fresh map is built for every render, so concurrent requests never share
+// (or mutate) each other's funcs.
+func (app *App[AppContext]) requestFuncs(r *http.Request, view any) template.FuncMap {
+	builtins := template.FuncMap{}
+	if r != nil {
+		builtins = RequestFuncMap(r)
+	}
+	builtins["urlFor"] = app.URLFor
+
+	var groupFuncs map[string]any
+	if templates := app.templateGroup(); templates != nil {
+		groupFuncs = templates.Funcs
+	}
+	funcs := template.FuncMap{}
+	for name, fn := range builtins {
+		if own, ok := groupFuncs[name]; ok && !isRequestFuncPlaceholder(name, own) {
+			continue
+		}
+		funcs[name] = fn
+	}
+	if r == nil {
+		return funcs
+	}
+	if app.RequestFuncs != nil {
+		for name, fn := range app.RequestFuncs(r, view) {
+			funcs[name] = fn
+		}
+	}
+	return funcs
+}
+
 // templateNameFromType extracts the template name from a View type.
 // For *GameListingPage, returns "GameListingPage".
 func templateNameFromType[V any]() string {
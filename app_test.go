@@ -0,0 +1,74 @@
+package goapplib
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestRequestFuncsPrecedence checks that built-in request funcs replace
+// DefaultFuncMap's placeholders but not app funcs of the same name, and
+// that App.RequestFuncs wins over both.
+func TestRequestFuncsPrecedence(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/games/1", nil)
+
+	app := NewApp(struct{}{}, SetupTemplates())
+	funcs := app.requestFuncs(r, nil)
+	for _, name := range []string{"currentPath", "isActive", "flashes", "urlFor"} {
+		if _, ok := funcs[name]; !ok {
+			t.Errorf("built-in %s not bound over its placeholder", name)
+		}
+	}
+	if isActive := funcs["isActive"].(func(string) bool); !isActive("/games") {
+		t.Errorf(`isActive "/games" = false on /games/1`)
+	}
+
+	ownIsActive := func(tab string) bool { return tab == "mine" }
+	app.Templates.AddFuncs(template.FuncMap{"isActive": ownIsActive, "currentPath": func() string { return "own" }})
+	funcs = app.requestFuncs(r, nil)
+	if _, ok := funcs["isActive"]; ok {
+		t.Errorf("built-in isActive bound over the app's own func")
+	}
+	if _, ok := funcs["currentPath"]; ok {
+		t.Errorf("built-in currentPath bound over the app's own func")
+	}
+	if _, ok := funcs["urlFor"]; !ok {
+		t.Errorf("built-in urlFor not bound")
+	}
+
+	app.RequestFuncs = func(r *http.Request, view any) template.FuncMap {
+		return template.FuncMap{"currentPath": func() string { return "request" }}
+	}
+	funcs = app.requestFuncs(r, nil)
+	if got := funcs["currentPath"].(func() string)(); got != "request" {
+		t.Errorf("currentPath = %q, want App.RequestFuncs' func", got)
+	}
+}
+
+// TestEnableLocalDevWarnsWithoutPaths checks that local dev without template
+// paths names the loaders that will not be reloaded.
+func TestEnableLocalDevWarnsWithoutPaths(t *testing.T) {
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&out)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	app := NewApp(struct{}{}, SetupTemplatesFS(fstest.MapFS{}, TemplatesFS))
+	app.EnableLocalDev(ctx)
+	if got := out.String(); !strings.Contains(got, "*goapplib.FSLoader, *goapplib.FSLoader") {
+		t.Fatalf("warning %q does not name the FS loaders", got)
+	}
+
+	out.Reset()
+	NewApp(struct{}{}, SetupTemplatesFS(TemplatesFS)).EnableLocalDev(ctx, t.TempDir())
+	if out.Len() != 0 {
+		t.Fatalf("unexpected warning with a watched path: %q", out.String())
+	}
+}
@@ -0,0 +1,252 @@
+package goapplib
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bindSources are the struct tags Bind reads, in the order they are checked.
+var bindSources = []string{"path", "query", "header", "cookie"}
+
+// bindField is a struct field filled by Bind.
+type bindField struct {
+	index  []int
+	source string // One of bindSources
+	name   string
+	def    string // From the `default` tag
+}
+
+// bindPlans caches the bindFields of each struct type.
+var bindPlans sync.Map // reflect.Type -> []bindField
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind fills the fields of the struct dst points to from the request, based
+// on their tags:
+//
+//	type GameDetailPage struct {
+//	    GameId   string    `path:"gameId"`
+//	    Page     int       `query:"page" default:"1"`
+//	    Tags     []string  `query:"tag"`      // ?tag=a&tag=b
+//	    Since    time.Time `query:"since"`    // RFC 3339 or 2006-01-02
+//	    Theme    string    `cookie:"theme"`
+//	    ClientId string    `header:"X-Client-Id"`
+//	}
+//
+// Strings, bools, ints, uints, floats, time.Time, time.Duration,
+// encoding.TextUnmarshalers, pointers to these and slices of these are
+// supported. Missing or empty values leave the field alone, or set it from
+// the `default` tag. Fields of embedded structs are bound too.
+//
+// Register calls Bind before the view's mixins and Load, for views that are
+// pointers to structs. A value that does not convert is a 400 *StatusError.
+func Bind(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("goapplib: Bind needs a pointer to a struct, got %T", dst)
+	}
+	fields := bindFieldsOf(v.Elem().Type())
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var query url.Values
+	for _, f := range fields {
+		var values []string
+		switch f.source {
+		case "path":
+			if val := r.PathValue(f.name); val != "" {
+				values = []string{val}
+			}
+		case "query":
+			if query == nil {
+				query = r.URL.Query()
+			}
+			values = query[f.name]
+		case "header":
+			values = r.Header.Values(f.name)
+		case "cookie":
+			if c, err := r.Cookie(f.name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+		if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+			if f.def == "" {
+				continue
+			}
+			values = []string{f.def}
+		}
+
+		if err := setField(v.Elem().FieldByIndex(f.index), values); err != nil {
+			var typeErr *bindTypeError
+			if errors.As(err, &typeErr) {
+				return err
+			}
+			return NewStatusError(http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter %q", f.source, f.name), err)
+		}
+	}
+	return nil
+}
+
+// bindView binds view for Register. Views that are not pointers to structs,
+// or have no tagged fields, are left alone.
+func bindView(r *http.Request, view any) error {
+	t := reflect.TypeOf(view)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	if len(bindFieldsOf(t.Elem())) == 0 {
+		return nil
+	}
+	return Bind(r, view)
+}
+
+// bindFieldsOf returns the (cached) bindFields of struct type t.
+func bindFieldsOf(t reflect.Type) []bindField {
+	if fields, ok := bindPlans.Load(t); ok {
+		return fields.([]bindField)
+	}
+	fields := collectBindFields(t, nil)
+	bindPlans.Store(t, fields)
+	return fields
+}
+
+func collectBindFields(t reflect.Type, parent []int) []bindField {
+	var fields []bindField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+
+		tagged := false
+		for _, source := range bindSources {
+			if name, ok := field.Tag.Lookup(source); ok && name != "" && name != "-" {
+				if field.IsExported() {
+					fields = append(fields, bindField{index: index, source: source, name: name, def: field.Tag.Get("default")})
+				}
+				tagged = true
+				break
+			}
+		}
+		if !tagged && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectBindFields(field.Type, index)...)
+		}
+	}
+	return fields
+}
+
+// bindTypeError reports a field whose type Bind cannot convert to.
+// It is a programming error, so it is not turned into a 400.
+type bindTypeError struct {
+	typ reflect.Type
+}
+
+func (e *bindTypeError) Error() string {
+	return "goapplib: cannot bind values to type " + e.typ.String()
+}
+
+// setField converts values into field, filling slices with every value.
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && !reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, val := range values {
+			if err := setValue(slice.Index(i), val); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, values[0])
+}
+
+// setValue converts s into v.
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == timeType:
+		t, err := parseTime(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case reflect.PointerTo(v.Type()).Implements(textUnmarshalerType):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		if strings.EqualFold(s, "on") { // Checkboxes
+			v.SetBool(true)
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return &bindTypeError{typ: v.Type()}
+	}
+	return nil
+}
+
+// timeLayouts are the time formats Bind accepts: RFC 3339 and the formats
+// of HTML datetime-local and date inputs.
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+func parseTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
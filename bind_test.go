@@ -0,0 +1,161 @@
+package goapplib
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// level is a TextUnmarshaler accepting "low" and "high".
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type bindPaging struct {
+	Page int `query:"page" default:"1"`
+}
+
+type bindTarget struct {
+	bindPaging
+
+	Id       string        `path:"id"`
+	Count    int           `query:"count"`
+	Size     uint8         `query:"size"`
+	Ratio    float64       `query:"ratio"`
+	Active   bool          `query:"active"`
+	Since    time.Time     `query:"since"`
+	Wait     time.Duration `query:"wait"`
+	Tags     []string      `query:"tag"`
+	Ids      []int         `query:"id"`
+	Level    level         `query:"level"`
+	Limit    *int          `query:"limit"`
+	Client   string        `header:"X-Client-Id"`
+	Theme    string        `cookie:"theme"`
+	Untagged string
+	hidden   string `query:"hidden"`
+}
+
+func TestBind(t *testing.T) {
+	limit := 50
+	tests := []struct {
+		name  string
+		query string
+		want  bindTarget
+	}{
+		{"defaults", "", bindTarget{bindPaging: bindPaging{Page: 1}}},
+		{"embedded", "page=3", bindTarget{bindPaging: bindPaging{Page: 3}}},
+		{"numbers", "count=-7&size=200&ratio=0.5", bindTarget{bindPaging: bindPaging{1}, Count: -7, Size: 200, Ratio: 0.5}},
+		{"bool", "active=true", bindTarget{bindPaging: bindPaging{1}, Active: true}},
+		{"checkbox", "active=on", bindTarget{bindPaging: bindPaging{1}, Active: true}},
+		{"rfc3339", "since=2024-03-01T10:00:00Z", bindTarget{bindPaging: bindPaging{1}, Since: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}},
+		{"date", "since=2024-03-01", bindTarget{bindPaging: bindPaging{1}, Since: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}},
+		{"duration", "wait=1m30s", bindTarget{bindPaging: bindPaging{1}, Wait: 90 * time.Second}},
+		{"slices", "tag=a&tag=b&id=1&id=2", bindTarget{bindPaging: bindPaging{1}, Tags: []string{"a", "b"}, Ids: []int{1, 2}}},
+		{"text unmarshaler", "level=high", bindTarget{bindPaging: bindPaging{1}, Level: 2}},
+		{"pointer", "limit=50", bindTarget{bindPaging: bindPaging{1}, Limit: &limit}},
+		{"empty keeps default", "page=", bindTarget{bindPaging: bindPaging{Page: 1}}},
+		{"unexported and untagged", "hidden=x&Untagged=y", bindTarget{bindPaging: bindPaging{1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			var got bindTarget
+			if err := Bind(r, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindPathHeaderCookie(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetPathValue("id", "g42")
+	r.Header.Set("X-Client-Id", "c7")
+	r.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+
+	var got bindTarget
+	if err := Bind(r, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != "g42" || got.Client != "c7" || got.Theme != "dark" {
+		t.Fatalf("got id %q, client %q, theme %q", got.Id, got.Client, got.Theme)
+	}
+}
+
+func TestBindBadInputIs400(t *testing.T) {
+	for _, query := range []string{
+		"count=abc",
+		"size=300", // Overflows uint8
+		"ratio=x",
+		"active=maybe",
+		"since=yesterday",
+		"wait=soon",
+		"id=1&id=two",
+		"level=medium",
+		"limit=many",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		err := Bind(r, &bindTarget{})
+		if status, _ := ErrorStatus(err); err == nil || status != http.StatusBadRequest {
+			t.Errorf("%s: got %v (status %d), want a 400", query, err, status)
+		}
+		if name, _, _ := strings.Cut(query, "="); err != nil && !strings.Contains(err.Error(), name) {
+			t.Errorf("%s: error %q does not name the parameter", query, err)
+		}
+	}
+}
+
+func TestBindUnsupportedTypeIsNot400(t *testing.T) {
+	var dst struct {
+		Ch chan int `query:"ch"`
+	}
+	err := Bind(httptest.NewRequest(http.MethodGet, "/?ch=1", nil), &dst)
+	if status, _ := ErrorStatus(err); err == nil || status == http.StatusBadRequest {
+		t.Fatalf("got %v, want a programming error", err)
+	}
+}
+
+// boundPage is bound by Register before Load.
+type boundPage struct {
+	Page int `query:"page" default:"1"`
+}
+
+func (p *boundPage) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	w.Write([]byte(strings.Repeat("p", p.Page)))
+	return nil, true
+}
+
+func TestRegisterBindsViews(t *testing.T) {
+	mux := Register[*boundPage](NewApp(struct{}{}, nil), nil, "/")
+	for query, want := range map[string]struct {
+		status int
+		body   string
+	}{
+		"":        {http.StatusOK, "p"},
+		"?page=3": {http.StatusOK, "ppp"},
+		"?page=x": {http.StatusBadRequest, ""},
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+query, nil))
+		if w.Code != want.status || (want.body != "" && w.Body.String() != want.body) {
+			t.Errorf("%q: got %d %q, want %d %q", query, w.Code, w.Body.String(), want.status, want.body)
+		}
+	}
+}
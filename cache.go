@@ -0,0 +1,211 @@
+package goapplib
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FragmentCache stores rendered output. Implement it to plug in a shared
+// cache (e.g., Redis); NewLRUCache provides an in-memory one.
+type FragmentCache interface {
+	// Get returns the cached value for key, if present and not expired.
+	Get(key string) ([]byte, bool)
+
+	// Set stores value under key for ttl (0 means no expiry), tagged with tags.
+	Set(key string, value []byte, ttl time.Duration, tags []string)
+
+	// Delete removes key.
+	Delete(key string)
+
+	// DeleteTag removes every entry tagged with tag.
+	DeleteTag(tag string)
+}
+
+// CacheableView is implemented by views whose rendered output can be cached.
+// CacheKey is called after Load; an empty key disables caching for that
+// request. The key must capture everything the output depends on (user,
+// query parameters, ...).
+type CacheableView interface {
+	CacheKey(r *http.Request) string
+	CacheTTL() time.Duration
+}
+
+// CacheTagger is optionally implemented by CacheableViews to tag their cache
+// entries, so a mutation can invalidate all of them at once with
+// App.InvalidateCacheTag.
+type CacheTagger interface {
+	CacheTags() []string
+}
+
+// cacheKeyTag is the tag every entry gets so that all template variants of a
+// key (full page, fragment) can be invalidated together.
+func cacheKeyTag(key string) string {
+	return "key:" + key
+}
+
+// InvalidateCacheKey removes the cached output for a CacheableView key.
+func (app *App[AppContext]) InvalidateCacheKey(key string) {
+	if app.Cache != nil {
+		app.Cache.DeleteTag(cacheKeyTag(key))
+	}
+}
+
+// InvalidateCacheTag removes all cached output tagged with tag (see CacheTagger).
+func (app *App[AppContext]) InvalidateCacheTag(tag string) {
+	if app.Cache != nil {
+		app.Cache.DeleteTag(tag)
+	}
+}
+
+// renderCached writes the output of render for view, serving it from
+// App.Cache when view is a CacheableView. variant identifies the template
+// being rendered so full pages and fragments of one key are cached apart.
+// Returns handled=false if the view is not cacheable and nothing was done.
+//
+// Only GET and HEAD responses with status 200 are read from or stored in the
+// cache: a validation re-render after a POST must show its own errors, and an
+// error page must not be served to later GETs. Pages showing flash messages
+// are never cached.
+func (app *App[AppContext]) renderCached(w http.ResponseWriter, r *http.Request, view any, variant string, render func(out io.Writer) error) (handled bool, err error) {
+	cv, ok := view.(CacheableView)
+	if !ok || app.Cache == nil || app.RenderTemplateFunc != nil || len(Flashes(r)) > 0 {
+		return false, nil
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false, nil
+	}
+	if overridesStatus(w) || statusCodeOf(view) != http.StatusOK {
+		return false, nil // Re-render with errors, or a status set by the view or BeforeRender
+	}
+	key := cv.CacheKey(r)
+	if key == "" {
+		return false, nil
+	}
+
+	entryKey := key + "@" + variant
+	if cached, ok := app.Cache.Get(entryKey); ok {
+		return true, writeBuffered(w, statusCodeOf(view), bytes.NewBuffer(cached))
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := render(buf); err != nil {
+		return true, err
+	}
+
+	tags := []string{cacheKeyTag(key)}
+	if ct, ok := view.(CacheTagger); ok {
+		tags = append(tags, ct.CacheTags()...)
+	}
+	app.Cache.Set(entryKey, bytes.Clone(buf.Bytes()), cv.CacheTTL(), tags)
+	return true, writeBuffered(w, statusCodeOf(view), buf)
+}
+
+// LRUCache is an in-memory FragmentCache that evicts the least recently used
+// entries beyond a maximum count. It is safe for concurrent use.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // Front is most recently used
+	entries    map[string]*list.Element
+	tags       map[string]map[string]struct{}
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+// NewLRUCache creates an LRUCache holding at most maxEntries entries.
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+		tags:       map[string]map[string]struct{}{},
+	}
+}
+
+// Get implements FragmentCache.
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set implements FragmentCache.
+func (c *LRUCache) Set(key string, value []byte, ttl time.Duration, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	entry := &lruEntry{key: key, value: value, tags: tags}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = map[string]struct{}{}
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete implements FragmentCache.
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// DeleteTag implements FragmentCache.
+func (c *LRUCache) DeleteTag(tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.tags[tag] {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+	}
+	delete(c.tags, tag)
+}
+
+// remove drops an entry and its tag references. Caller must hold c.mu.
+func (c *LRUCache) remove(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	c.order.Remove(elem)
+	delete(c.entries, entry.key)
+	for _, tag := range entry.tags {
+		if keys := c.tags[tag]; keys != nil {
+			delete(keys, entry.key)
+			if len(keys) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package goapplib
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// HTTPError is implemented by errors that map to an HTTP status code.
+// Return one from View.Load to control the status of the error response.
+type HTTPError interface {
+	error
+	StatusCoder
+}
+
+// StatusError is an error with an HTTP status code and a message that is safe
+// to show to users. Err, if set, holds the underlying cause; it is logged but
+// never shown.
+type StatusError struct {
+	Status  int
+	Message string
+	Err     error
+}
+
+// NewStatusError creates a StatusError. An empty message uses the status text.
+func NewStatusError(status int, message string, err error) *StatusError {
+	return &StatusError{Status: status, Message: message, Err: err}
+}
+
+// NotFound returns a 404 error with the given user-facing message.
+func NotFound(message string) *StatusError {
+	return NewStatusError(http.StatusNotFound, message, nil)
+}
+
+// BadRequest returns a 400 error with the given user-facing message.
+func BadRequest(message string) *StatusError {
+	return NewStatusError(http.StatusBadRequest, message, nil)
+}
+
+// Unauthorized returns a 401 error with the given user-facing message.
+func Unauthorized(message string) *StatusError {
+	return NewStatusError(http.StatusUnauthorized, message, nil)
+}
+
+// Forbidden returns a 403 error with the given user-facing message.
+func Forbidden(message string) *StatusError {
+	return NewStatusError(http.StatusForbidden, message, nil)
+}
+
+func (e *StatusError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode implements StatusCoder.
+func (e *StatusError) StatusCode() int {
+	return e.Status
+}
+
+// ErrorStatus maps an error to an HTTP status code and a user-safe message.
+// Errors implementing HTTPError (anywhere in the chain) keep their status,
+// context.DeadlineExceeded is a 504 and everything else is a 500. Only
+// StatusError messages are passed through; other errors get the generic
+// status text so internal details never leak.
+func ErrorStatus(err error) (status int, message string) {
+	status = http.StatusInternalServerError
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode() > 0 {
+		status = httpErr.StatusCode()
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Message != "" {
+		message = statusErr.Message
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return
+}
+
+// HandleError logs err and writes the matching error response
+// (see ErrorStatus and RenderError). A *Redirect is not logged but written
+// as a redirect.
+func (app *App[AppContext]) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	if rd, ok := asRedirect(err); ok {
+		rd.ServeHTTP(w, r)
+		return
+	}
+	failRequest(r, err)
+	status, message := ErrorStatus(err)
+	log.Printf("Error handling %s %s [%d]: %v", r.Method, r.URL.Path, status, err)
+	app.RenderError(w, r, status, message)
+}
@@ -0,0 +1,289 @@
+package goapplib
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Flash types, matching the styles of the Toast template.
+const (
+	FlashSuccess = "success"
+	FlashError   = "error"
+	FlashWarning = "warning"
+	FlashInfo    = "info"
+)
+
+// Flash is a one-time message shown to the user on the next page they see,
+// typically after a redirect. It renders with the Toast template.
+type Flash struct {
+	Type    string `json:"type"` // FlashSuccess, FlashError, FlashWarning or FlashInfo
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+// Dismissible is read by the Toast template.
+func (f Flash) Dismissible() bool { return true }
+
+// AutoDismiss is read by the Toast template; errors stay until dismissed.
+func (f Flash) AutoDismiss() bool { return f.Type != FlashError }
+
+// DismissAfter is read by the Toast template (0 uses its default).
+func (f Flash) DismissAfter() int { return 0 }
+
+// FlashStore keeps queued flashes between requests. CookieFlashStore is the
+// default; implement it to keep them in a server-side session instead.
+type FlashStore interface {
+	// Load returns the flashes saved for the client of r.
+	Load(r *http.Request) []Flash
+
+	// Save replaces the saved flashes of the client; an empty list clears them.
+	Save(w http.ResponseWriter, r *http.Request, flashes []Flash) error
+}
+
+// CookieFlashStore keeps flashes in a cookie signed with HMAC-SHA256, so
+// clients cannot inject messages.
+type CookieFlashStore struct {
+	Name   string // Cookie name, "_flash" by default
+	Secret []byte
+}
+
+// NewCookieFlashStore creates a CookieFlashStore signing with secret.
+// Use the same secret on all instances of a server.
+func NewCookieFlashStore(secret []byte) *CookieFlashStore {
+	return &CookieFlashStore{Name: "_flash", Secret: secret}
+}
+
+// Load implements FlashStore. Cookies with a bad signature are ignored.
+func (s *CookieFlashStore) Load(r *http.Request) []Flash {
+	c, err := r.Cookie(s.Name)
+	if err != nil {
+		return nil
+	}
+	payload, sig, ok := strings.Cut(c.Value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(payload))) {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil
+	}
+	var flashes []Flash
+	if json.Unmarshal(data, &flashes) != nil {
+		return nil
+	}
+	return flashes
+}
+
+// Save implements FlashStore.
+func (s *CookieFlashStore) Save(w http.ResponseWriter, r *http.Request, flashes []Flash) error {
+	c := &http.Cookie{
+		Name:     s.Name,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if len(flashes) == 0 {
+		c.MaxAge = -1
+	} else {
+		data, err := json.Marshal(flashes)
+		if err != nil {
+			return err
+		}
+		payload := base64.RawURLEncoding.EncodeToString(data)
+		c.Value = payload + "." + s.sign(payload)
+	}
+
+	// Replace a cookie set earlier in this request rather than adding another
+	header := w.Header()
+	cookies := header.Values("Set-Cookie")
+	header.Del("Set-Cookie")
+	for _, cookie := range cookies {
+		if !strings.HasPrefix(cookie, s.Name+"=") {
+			header.Add("Set-Cookie", cookie)
+		}
+	}
+	http.SetCookie(w, c)
+	return nil
+}
+
+func (s *CookieFlashStore) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+var (
+	defaultFlashStoreOnce sync.Once
+	defaultFlashStore     *CookieFlashStore
+)
+
+// flashStore returns App.Flashes, or a cookie store with a random key.
+func (app *App[AppContext]) flashStore() FlashStore {
+	if app.Flashes != nil {
+		return app.Flashes
+	}
+	defaultFlashStoreOnce.Do(func() {
+		secret := make([]byte, 32)
+		rand.Read(secret)
+		defaultFlashStore = NewCookieFlashStore(secret)
+	})
+	return defaultFlashStore
+}
+
+// flashState tracks the flashes of a request served by Register.
+type flashState struct {
+	mu      sync.Mutex
+	loaded  bool
+	queued  []Flash // Saved for the client, including ones added in this request
+	current []Flash // Taken for this render
+}
+
+type flashStateKey struct{}
+
+// withFlashState attaches a flashState to r so flashes added while serving it
+// can still be shown by its own render.
+func withFlashState(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), flashStateKey{}, &flashState{}))
+}
+
+func flashStateOf(r *http.Request) *flashState {
+	state, _ := r.Context().Value(flashStateKey{}).(*flashState)
+	return state
+}
+
+// AddFlash queues a flash message for the client. It is shown by the next
+// page rendered for them: this one if the view renders, or the target of a
+// redirect. Call it before anything is written to w.
+//
+//	app.AddFlash(w, r, goapplib.FlashSuccess, "Game saved")
+//	return goapplib.RedirectTo("/games/" + id), false
+func (app *App[AppContext]) AddFlash(w http.ResponseWriter, r *http.Request, flashType, message string) {
+	app.AddFlashes(w, r, Flash{Type: flashType, Message: message})
+}
+
+// AddFlashes queues one or more flash messages (see AddFlash).
+func (app *App[AppContext]) AddFlashes(w http.ResponseWriter, r *http.Request, flashes ...Flash) {
+	store := app.flashStore()
+	state := flashStateOf(r)
+	if state == nil {
+		// Not served by Register: just append to what the client has
+		if err := store.Save(w, r, append(store.Load(r), flashes...)); err != nil {
+			log.Printf("Error saving flash messages: %v", err)
+		}
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.loaded {
+		state.queued, state.loaded = store.Load(r), true
+	}
+	state.queued = append(state.queued, flashes...)
+	if err := store.Save(w, r, state.queued); err != nil {
+		log.Printf("Error saving flash messages: %v", err)
+	}
+}
+
+// takeFlashes takes the queued flashes of the client for the response being
+// rendered: full pages render them with the flashes template func, and HTMX
+// fragment requests get them as a "showToast" HX-Trigger. They are only
+// removed from the store once the response is written through the returned
+// ResponseWriter, so a failed render, written to w, leaves them queued.
+func (app *App[AppContext]) takeFlashes(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	state := flashStateOf(r)
+	if state == nil {
+		return w
+	}
+	store := app.flashStore()
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.loaded {
+		state.queued, state.loaded = store.Load(r), true
+	}
+	if len(state.queued) == 0 {
+		return w
+	}
+	taken := state.queued
+	trigger := IsHtmxRequest(r) && !IsBoostedRequest(r)
+	if !trigger {
+		state.current = append(state.current, taken...)
+	}
+	return &flashWriter{ResponseWriter: w, commit: func() {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		state.queued = state.queued[len(taken):] // Keep flashes added since
+		if err := store.Save(w, r, state.queued); err != nil {
+			log.Printf("Error clearing flash messages: %v", err)
+			return
+		}
+		if trigger {
+			if len(taken) == 1 {
+				NewHtmxResponse(w).AddTrigger("showToast", taken[0])
+			} else {
+				NewHtmxResponse(w).AddTrigger("showToast", map[string]any{"toasts": taken})
+			}
+		}
+	}}
+}
+
+// untakeFlashes forgets the flashes taken for a render that failed, so the
+// error page does not show them. They stay queued for the next page.
+func untakeFlashes(r *http.Request) {
+	state := flashStateOf(r)
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.current = nil
+}
+
+// flashWriter removes the flashes taken by takeFlashes from the store when
+// the response is written.
+type flashWriter struct {
+	http.ResponseWriter
+	commit func()
+}
+
+func (fw *flashWriter) WriteHeader(code int) {
+	fw.done()
+	fw.ResponseWriter.WriteHeader(code)
+}
+
+func (fw *flashWriter) Write(b []byte) (int, error) {
+	fw.done()
+	return fw.ResponseWriter.Write(b)
+}
+
+func (fw *flashWriter) done() {
+	if fw.commit != nil {
+		fw.commit()
+		fw.commit = nil
+	}
+}
+
+func (fw *flashWriter) Unwrap() http.ResponseWriter {
+	return fw.ResponseWriter
+}
+
+// Flashes returns the flash messages taken for the page being rendered for r.
+// Templates use the flashes func instead.
+func Flashes(r *http.Request) []Flash {
+	state := flashStateOf(r)
+	if state == nil {
+		return nil
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return append([]Flash(nil), state.current...)
+}
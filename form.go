@@ -0,0 +1,343 @@
+package goapplib
+
+import (
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxFormMemory is the part of a multipart body DecodeForm keeps in memory;
+// larger files are stored in temporary files.
+const maxFormMemory = 32 << 20
+
+// FormValidator is optionally implemented by form structs for checks that
+// declarative rules cannot express (e.g., "username is taken"). ValidateForm
+// runs after the rules and adds messages to errs, keyed by form field name.
+type FormValidator interface {
+	ValidateForm(r *http.Request, errs map[string]string)
+}
+
+// ValidationRule checks one field against a `validate` tag rule. param is
+// the text after "=" (e.g., "8" for min=8) and parent is the struct holding
+// the field. It returns an error message, or "" if the value is valid.
+type ValidationRule func(field reflect.Value, param string, parent reflect.Value) string
+
+var (
+	validationRulesMu sync.RWMutex
+	validationRules   = map[string]ValidationRule{
+		"email":   validateEmail,
+		"min":     validateMin,
+		"max":     validateMax,
+		"eqfield": validateEqField,
+		"oneof":   validateOneOf,
+	}
+)
+
+// RegisterValidationRule adds a custom rule for `validate` tags, e.g.
+//
+//	goapplib.RegisterValidationRule("slug", func(f reflect.Value, _ string, _ reflect.Value) string {
+//	    if !slugRe.MatchString(f.String()) {
+//	        return "may only contain lowercase letters, digits and dashes"
+//	    }
+//	    return ""
+//	})
+func RegisterValidationRule(name string, rule ValidationRule) {
+	validationRulesMu.Lock()
+	defer validationRulesMu.Unlock()
+	validationRules[name] = rule
+}
+
+// formField is a struct field filled or checked by DecodeForm.
+type formField struct {
+	index    []int
+	name     string // Form field name, or the Go field name without a form tag
+	bind     bool   // Has a form tag
+	rules    []string
+	required bool
+}
+
+// formPlans caches the formFields of each struct type.
+var formPlans sync.Map // reflect.Type -> []formField
+
+// DecodeForm binds an application/x-www-form-urlencoded or multipart form
+// body into the fields of the struct dst points to and validates them:
+//
+//	type RegisterPage struct {
+//	    Email    string                `form:"email" validate:"required,email"`
+//	    Password string                `form:"password" validate:"required,min=8"`
+//	    Verify   string                `form:"verifyPassword" validate:"eqfield=Password"`
+//	    Avatar   *multipart.FileHeader `form:"avatar"`
+//	    Errors   map[string]string
+//	}
+//
+// Fields are converted like Bind does; an absent checkbox sets a bool field
+// to false. Built-in rules are required, email, min and max (length for
+// strings and slices, value for numbers), eqfield and oneof (space
+// separated); see RegisterValidationRule for custom ones. Rules other than
+// required accept empty values. FormValidator adds custom checks.
+//
+// Errors are keyed by form field name. If dst has an Errors
+// map[string]string field, it is set to them so templates can show them next
+// to the inputs. If there are any, DecodeForm returns a *ValidationError,
+// which makes an action re-render its view with status 422.
+func DecodeForm(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("goapplib: DecodeForm needs a pointer to a struct, got %T", dst)
+	}
+	if err := parseForm(r); err != nil {
+		return NewStatusError(http.StatusBadRequest, "Invalid form data", err)
+	}
+
+	v = v.Elem()
+	errs := map[string]string{}
+	for _, f := range formFieldsOf(v.Type()) {
+		if !f.bind {
+			continue
+		}
+		if err := setFormField(v.FieldByIndex(f.index), r, f.name); err != nil {
+			errs[f.name] = "has an invalid value"
+		}
+	}
+	if err := validateFields(v, errs); err != nil {
+		return err
+	}
+	if fv, ok := dst.(FormValidator); ok {
+		fv.ValidateForm(r, errs)
+	}
+
+	if field := errorsField(v); field.IsValid() {
+		field.Set(reflect.ValueOf(errs))
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// errorsField returns the settable Errors map[string]string field of the
+// struct v, or the zero Value if it has none.
+func errorsField(v reflect.Value) reflect.Value {
+	field := v.FieldByName("Errors")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(map[string]string(nil)) || !field.CanSet() {
+		return reflect.Value{}
+	}
+	return field
+}
+
+// ValidateFields runs the `validate` tag rules of the struct dst points to
+// without reading a form, e.g. for data decoded from JSON. It returns the
+// failures keyed by form field name (or Go field name), or an empty map.
+func ValidateFields(dst any) (map[string]string, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("goapplib: ValidateFields needs a pointer to a struct, got %T", dst)
+	}
+	errs := map[string]string{}
+	if err := validateFields(v.Elem(), errs); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// parseForm parses the request body once, as multipart if it is one.
+func parseForm(r *http.Request) error {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		if r.MultipartForm == nil {
+			return r.ParseMultipartForm(maxFormMemory)
+		}
+		return nil
+	}
+	return r.ParseForm()
+}
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+// setFormField fills field from the form values or files named name.
+func setFormField(field reflect.Value, r *http.Request, name string) error {
+	switch {
+	case field.Type() == fileHeaderType:
+		if r.MultipartForm != nil && len(r.MultipartForm.File[name]) > 0 {
+			field.Set(reflect.ValueOf(r.MultipartForm.File[name][0]))
+		}
+		return nil
+	case field.Kind() == reflect.Slice && field.Type().Elem() == fileHeaderType:
+		if r.MultipartForm != nil {
+			field.Set(reflect.ValueOf(r.MultipartForm.File[name]))
+		}
+		return nil
+	}
+
+	values := r.PostForm[name]
+	if len(values) == 0 {
+		if field.Kind() == reflect.Bool {
+			field.SetBool(false) // Unchecked checkbox
+		}
+		return nil
+	}
+	if len(values) == 1 && values[0] == "" && field.Kind() != reflect.String {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	return setField(field, values)
+}
+
+// formFieldsOf returns the (cached) formFields of struct type t.
+func formFieldsOf(t reflect.Type) []formField {
+	if fields, ok := formPlans.Load(t); ok {
+		return fields.([]formField)
+	}
+	fields := collectFormFields(t, nil)
+	formPlans.Store(t, fields)
+	return fields
+}
+
+func collectFormFields(t reflect.Type, parent []int) []formField {
+	var fields []formField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+		formName, hasForm := field.Tag.Lookup("form")
+		validate := field.Tag.Get("validate")
+
+		if !hasForm && validate == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				fields = append(fields, collectFormFields(field.Type, index)...)
+			}
+			continue
+		}
+		if !field.IsExported() || formName == "-" {
+			continue
+		}
+
+		f := formField{index: index, name: formName, bind: hasForm && formName != ""}
+		if f.name == "" {
+			f.name = field.Name
+		}
+		for _, rule := range strings.Split(validate, ",") {
+			switch rule = strings.TrimSpace(rule); rule {
+			case "":
+			case "required":
+				f.required = true
+			default:
+				f.rules = append(f.rules, rule)
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// validateFields applies the `validate` rules of struct v, adding the first
+// failure of each field to errs unless it already has one.
+func validateFields(v reflect.Value, errs map[string]string) error {
+	validationRulesMu.RLock()
+	defer validationRulesMu.RUnlock()
+
+	for _, f := range formFieldsOf(v.Type()) {
+		if _, failed := errs[f.name]; failed {
+			continue
+		}
+		field := v.FieldByIndex(f.index)
+		if isEmptyValue(field) {
+			if f.required {
+				errs[f.name] = "is required"
+			}
+			continue
+		}
+		parent := v.FieldByIndex(f.index[:len(f.index)-1])
+		for _, rule := range f.rules {
+			name, param, _ := strings.Cut(rule, "=")
+			check, ok := validationRules[name]
+			if !ok {
+				return fmt.Errorf("goapplib: unknown validation rule %q on %s.%s", name, v.Type(), f.name)
+			}
+			if msg := check(field, param, parent); msg != "" {
+				errs[f.name] = msg
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// isEmptyValue reports whether a field counts as missing for required.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+func validateEmail(field reflect.Value, _ string, _ reflect.Value) string {
+	s := strings.TrimSpace(field.String())
+	if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+// sizeOf returns the length of strings and collections and the value of
+// numbers, as compared by min and max.
+func sizeOf(field reflect.Value) (size float64, unit string) {
+	switch field.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(field.String())), " characters"
+	case reflect.Slice, reflect.Map:
+		return float64(field.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), ""
+	}
+	return 0, ""
+}
+
+func validateMin(field reflect.Value, param string, _ reflect.Value) string {
+	limit, err := strconv.ParseFloat(param, 64)
+	if size, unit := sizeOf(field); err == nil && size < limit {
+		return "must be at least " + param + unit
+	}
+	return ""
+}
+
+func validateMax(field reflect.Value, param string, _ reflect.Value) string {
+	limit, err := strconv.ParseFloat(param, 64)
+	if size, unit := sizeOf(field); err == nil && size > limit {
+		return "must be at most " + param + unit
+	}
+	return ""
+}
+
+func validateEqField(field reflect.Value, param string, parent reflect.Value) string {
+	other := parent.FieldByName(param)
+	if !other.IsValid() || !reflect.DeepEqual(field.Interface(), other.Interface()) {
+		return "must match " + param
+	}
+	return ""
+}
+
+func validateOneOf(field reflect.Value, param string, _ reflect.Value) string {
+	value := fmt.Sprint(field.Interface())
+	options := strings.Fields(param)
+	for _, option := range options {
+		if value == option {
+			return ""
+		}
+	}
+	return "must be one of " + strings.Join(options, ", ")
+}
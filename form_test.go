@@ -0,0 +1,215 @@
+package goapplib
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type signupForm struct {
+	Email    string   `form:"email" validate:"required,email"`
+	Password string   `form:"password" validate:"required,min=8"`
+	Verify   string   `form:"verifyPassword" validate:"eqfield=Password"`
+	Age      int      `form:"age" validate:"min=13,max=120"`
+	Plan     string   `form:"plan" validate:"oneof=free pro"`
+	Tags     []string `form:"tag" validate:"max=2"`
+	Terms    bool     `form:"terms" validate:"required"`
+	Nickname string   `validate:"max=5"` // Checked but not bound: keyed by Go name
+	Errors   map[string]string
+}
+
+// validSignup is a form that passes every rule.
+func validSignup() url.Values {
+	return url.Values{
+		"email":          {"ann@example.com"},
+		"password":       {"hunter22"},
+		"verifyPassword": {"hunter22"},
+		"age":            {"30"},
+		"plan":           {"pro"},
+		"tag":            {"a", "b"},
+		"terms":          {"on"},
+	}
+}
+
+func postValues(values url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestDecodeFormBindsValidForm(t *testing.T) {
+	var form signupForm
+	if err := DecodeForm(postValues(validSignup()), &form); err != nil {
+		t.Fatal(err)
+	}
+	want := signupForm{
+		Email: "ann@example.com", Password: "hunter22", Verify: "hunter22",
+		Age: 30, Plan: "pro", Tags: []string{"a", "b"}, Terms: true,
+		Errors: map[string]string{},
+	}
+	if !reflect.DeepEqual(form, want) {
+		t.Fatalf("got %+v, want %+v", form, want)
+	}
+}
+
+func TestDecodeFormRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(url.Values)
+		wantKey string
+		wantMsg string
+	}{
+		{"required", func(v url.Values) { v.Set("email", "  ") }, "email", "is required"},
+		{"required bool", func(v url.Values) { v.Del("terms") }, "terms", "is required"},
+		{"email", func(v url.Values) { v.Set("email", "ann at example") }, "email", "must be a valid email address"},
+		{"email with name", func(v url.Values) { v.Set("email", "Ann <ann@example.com>") }, "email", "must be a valid email address"},
+		{"min length", func(v url.Values) { v.Set("password", "short"); v.Set("verifyPassword", "short") }, "password", "must be at least 8 characters"},
+		{"min value", func(v url.Values) { v.Set("age", "12") }, "age", "must be at least 13"},
+		{"max value", func(v url.Values) { v.Set("age", "121") }, "age", "must be at most 120"},
+		{"max items", func(v url.Values) { v["tag"] = []string{"a", "b", "c"} }, "tag", "must be at most 2 items"},
+		{"eqfield", func(v url.Values) { v.Set("verifyPassword", "hunter23") }, "verifyPassword", "must match Password"},
+		{"oneof", func(v url.Values) { v.Set("plan", "gold") }, "plan", "must be one of free, pro"},
+		{"invalid value", func(v url.Values) { v.Set("age", "old") }, "age", "has an invalid value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := validSignup()
+			tt.change(values)
+			var form signupForm
+			err := DecodeForm(postValues(values), &form)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want a *ValidationError", err)
+			}
+			want := map[string]string{tt.wantKey: tt.wantMsg}
+			if !reflect.DeepEqual(validationErr.Fields, want) || !reflect.DeepEqual(form.Errors, want) {
+				t.Fatalf("got fields %v and Errors %v, want %v", validationErr.Fields, form.Errors, want)
+			}
+			if status, _ := ErrorStatus(err); status != http.StatusUnprocessableEntity {
+				t.Fatalf("got status %d, want 422", status)
+			}
+		})
+	}
+}
+
+func TestDecodeFormOptionalRulesAcceptEmpty(t *testing.T) {
+	values := validSignup()
+	for _, name := range []string{"verifyPassword", "age", "plan", "tag"} {
+		values.Del(name)
+	}
+	values.Set("password", "") // required still applies
+	var form signupForm
+	err := DecodeForm(postValues(values), &form)
+	want := map[string]string{"password": "is required"}
+	if err == nil || !reflect.DeepEqual(form.Errors, want) {
+		t.Fatalf("got %v, Errors %v, want %v", err, form.Errors, want)
+	}
+}
+
+func TestDecodeFormKeysUntaggedFieldsByGoName(t *testing.T) {
+	form := signupForm{Nickname: "toolong"}
+	err := DecodeForm(postValues(validSignup()), &form)
+	if err == nil || form.Errors["Nickname"] != "must be at most 5 characters" {
+		t.Fatalf("got %v, Errors %v", err, form.Errors)
+	}
+}
+
+func TestDecodeFormCustomRule(t *testing.T) {
+	RegisterValidationRule("lowercase", func(f reflect.Value, _ string, _ reflect.Value) string {
+		if f.String() != strings.ToLower(f.String()) {
+			return "must be lowercase"
+		}
+		return ""
+	})
+	var form struct {
+		Slug   string `form:"slug" validate:"required,lowercase"`
+		Errors map[string]string
+	}
+	for slug, want := range map[string]string{"my-game": "", "My-Game": "must be lowercase"} {
+		err := DecodeForm(postValues(url.Values{"slug": {slug}}), &form)
+		if form.Errors["slug"] != want || (err == nil) != (want == "") {
+			t.Errorf("%q: got %v, Errors %v, want %q", slug, err, form.Errors, want)
+		}
+	}
+}
+
+func TestDecodeFormUnknownRule(t *testing.T) {
+	var form struct {
+		Name string `form:"name" validate:"nosuchrule"`
+	}
+	err := DecodeForm(postValues(url.Values{"name": {"x"}}), &form)
+	var validationErr *ValidationError
+	if err == nil || errors.As(err, &validationErr) {
+		t.Fatalf("got %v, want a programming error", err)
+	}
+}
+
+// checkedForm adds a FormValidator check after the rules.
+type checkedForm struct {
+	Username string `form:"username" validate:"required"`
+	Errors   map[string]string
+}
+
+func (f *checkedForm) ValidateForm(r *http.Request, errs map[string]string) {
+	if f.Username == "taken" {
+		errs["username"] = "is already taken"
+	}
+}
+
+func TestDecodeFormValidator(t *testing.T) {
+	var form checkedForm
+	err := DecodeForm(postValues(url.Values{"username": {"taken"}}), &form)
+	if err == nil || form.Errors["username"] != "is already taken" {
+		t.Fatalf("got %v, Errors %v", err, form.Errors)
+	}
+}
+
+func TestDecodeFormMultipart(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "Chess")
+	mw.WriteField("players", "2")
+	part, _ := mw.CreateFormFile("cover", "cover.png")
+	part.Write([]byte("png bytes"))
+	mw.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	var form struct {
+		Title   string                  `form:"title" validate:"required"`
+		Players int                     `form:"players" validate:"min=1"`
+		Cover   *multipart.FileHeader   `form:"cover" validate:"required"`
+		Extras  []*multipart.FileHeader `form:"extra"`
+	}
+	if err := DecodeForm(r, &form); err != nil {
+		t.Fatal(err)
+	}
+	if form.Title != "Chess" || form.Players != 2 || form.Cover == nil || form.Cover.Filename != "cover.png" || len(form.Extras) != 0 {
+		t.Fatalf("got %+v", form)
+	}
+}
+
+func TestDecodeFormBadBodyIs400(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x"))
+	r.Header.Set("Content-Type", "multipart/form-data; boundary=missing")
+	var form checkedForm
+	err := DecodeForm(r, &form)
+	if status, _ := ErrorStatus(err); status != http.StatusBadRequest {
+		t.Fatalf("got %v (status %d), want a 400", err, status)
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	errs, err := ValidateFields(&signupForm{Email: "ann@example.com", Password: "x", Verify: "y", Terms: true})
+	want := map[string]string{"password": "must be at least 8 characters", "verifyPassword": "must match Password"}
+	if err != nil || !reflect.DeepEqual(errs, want) {
+		t.Fatalf("got %v, %v, want %v", errs, err, want)
+	}
+}
@@ -0,0 +1,83 @@
+package goapplib
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"strings"
+
+	tmplr "github.com/panyam/templar"
+)
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// TemplatesFS holds goapplib's own templates (BasePage.html, components/...),
+// rooted at the templates directory. Add it last in SetupTemplatesFS so your
+// app's templates override the library defaults:
+//
+//	templates := goapplib.SetupTemplatesFS(os.DirFS("./templates"), goapplib.TemplatesFS)
+var TemplatesFS fs.FS = mustSubFS(embeddedTemplates, "templates")
+
+func mustSubFS(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// FSLoader is a templar TemplateLoader that reads templates from an fs.FS
+// (e.g., an embed.FS or os.DirFS).
+type FSLoader struct {
+	FS fs.FS
+
+	// Extensions tried when a name has no extension. Defaults to html, tmpl, tmplus.
+	Extensions []string
+}
+
+// NewFSLoader creates an FSLoader for the given file system.
+func NewFSLoader(fsys fs.FS) *FSLoader {
+	return &FSLoader{FS: fsys}
+}
+
+// Load implements tmplr.TemplateLoader.
+// Names starting with "./" or "../" are resolved relative to cwd; all other
+// names are resolved from the root of the file system.
+func (l *FSLoader) Load(name string, cwd string) ([]*tmplr.Template, error) {
+	name = l.resolve(name, cwd)
+
+	candidates := []string{name}
+	if path.Ext(name) == "" {
+		extensions := l.Extensions
+		if len(extensions) == 0 {
+			extensions = []string{"html", "tmpl", "tmplus"}
+		}
+		candidates = candidates[:0]
+		for _, ext := range extensions {
+			candidates = append(candidates, name+"."+ext)
+		}
+	}
+
+	for _, candidate := range candidates {
+		contents, err := fs.ReadFile(l.FS, candidate)
+		if err != nil {
+			continue
+		}
+		return []*tmplr.Template{{
+			Name:      candidate,
+			RawSource: contents,
+			Path:      candidate,
+		}}, nil
+	}
+	return nil, tmplr.TemplateNotFound
+}
+
+// resolve turns a template name into a clean, root-relative fs.FS path.
+func (l *FSLoader) resolve(name string, cwd string) string {
+	if strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		name = path.Join(cwd, name)
+	}
+	name = path.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
@@ -0,0 +1,70 @@
+package goapplib
+
+import (
+	"net/http"
+)
+
+// BeforeRenderer is optionally implemented by views to adjust the response
+// after Load (and any action) and before the view is rendered, e.g. to set
+// headers. Calling w.WriteHeader sets the status the render is sent with
+// instead of writing it right away. A returned error is handled like a Load
+// error and nothing is rendered.
+type BeforeRenderer interface {
+	BeforeRender(r *http.Request, w http.ResponseWriter) error
+}
+
+// TemplateOverrider is optionally implemented by views that pick their
+// template at runtime, e.g. an empty-state variant of a listing page.
+// TemplateFor returns a template spec ("path/file" or "path/file:Block", see
+// WithTemplate), or "" to keep the registered one. It is called after the
+// HTMX fragment choice of SmartRegister, so it overrides that too. With a
+// layout, only the page file is replaced.
+type TemplateOverrider interface {
+	TemplateFor(r *http.Request) string
+}
+
+// AfterRenderer is optionally implemented by views to run code once the
+// response has been written, e.g. for logging or warming a cache. err is
+// the render error, if any.
+type AfterRenderer interface {
+	AfterRender(r *http.Request, err error)
+}
+
+// headerWriter holds back the WriteHeader call of a BeforeRender hook so the
+// status can be applied when the rendered output is written.
+type headerWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (hw *headerWriter) WriteHeader(code int) {
+	hw.status = code
+}
+
+func (hw *headerWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
+
+// beforeRender runs the view's BeforeRender hook, if any, and returns the
+// writer to render to.
+func beforeRender(view any, w http.ResponseWriter, r *http.Request) (http.ResponseWriter, error) {
+	br, ok := view.(BeforeRenderer)
+	if !ok {
+		return w, nil
+	}
+	hw := &headerWriter{ResponseWriter: w}
+	if err := br.BeforeRender(r, hw); err != nil {
+		return w, err
+	}
+	if hw.status != 0 {
+		w = &statusWriter{ResponseWriter: w, status: hw.status}
+	}
+	return w, nil
+}
+
+// afterRender runs the view's AfterRender hook, if any.
+func afterRender(view any, r *http.Request, err error) {
+	if ar, ok := view.(AfterRenderer); ok {
+		ar.AfterRender(r, err)
+	}
+}
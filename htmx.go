@@ -3,6 +3,7 @@ package goapplib
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 // HtmxResponse provides helpers for setting HTMX response headers.
@@ -31,6 +32,27 @@ func (h *HtmxResponse) TriggerWithData(event string, data any) *HtmxResponse {
 	return h
 }
 
+// AddTrigger adds an event with data to HX-Trigger, keeping the events
+// already set on it (e.g., by an error toast or flash messages).
+func (h *HtmxResponse) AddTrigger(event string, data any) *HtmxResponse {
+	events := map[string]any{}
+	if existing := h.w.Header().Get("HX-Trigger"); existing != "" {
+		if json.Unmarshal([]byte(existing), &events) != nil {
+			// Plain comma-separated event names
+			for _, name := range strings.Split(existing, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					events[name] = nil
+				}
+			}
+		}
+	}
+	events[event] = data
+	if jsonData, err := json.Marshal(events); err == nil {
+		h.w.Header().Set("HX-Trigger", string(jsonData))
+	}
+	return h
+}
+
 // TriggerAfterSettle sets HX-Trigger-After-Settle header.
 func (h *HtmxResponse) TriggerAfterSettle(event string) *HtmxResponse {
 	h.w.Header().Set("HX-Trigger-After-Settle", event)
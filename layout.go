@@ -0,0 +1,71 @@
+package goapplib
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	tmplr "github.com/panyam/templar"
+)
+
+// WithLayout renders the page inside one or more layouts.
+// Each layout is a template spec ("path/file" or "path/file:Block").
+// Layouts are listed outermost first, e.g. an app shell followed by a
+// settings layout:
+//
+//	goapplib.Register[ProfileSettingsPage](app, mux, "/settings/profile",
+//	    goapplib.WithLayout("BasePage", "settings/SettingsLayout"))
+//
+// The layout files and then the page's own file are combined into a fresh
+// template set for this page only, and the outermost layout's block is
+// executed. Later files override blocks of earlier ones, so the page's
+// `{{ define "BodySection" }}` fills the layout's BodySection without a
+// wrapper block and without leaking into other pages.
+//
+// Calling WithLayout with no arguments disables a layout inherited from a
+// group (see RegisterGroup and MuxBuilder.Layout).
+func WithLayout(layouts ...string) Option {
+	return func(o *options) {
+		o.layouts = append([]string{}, layouts...)
+	}
+}
+
+// layoutRoot builds a root template that includes each layout file in order
+// followed by the page file, and returns it with the block to execute.
+func layoutRoot(layouts []string, templateFileName string) (root *tmplr.Template, entry string) {
+	var src strings.Builder
+	names := make([]string, 0, len(layouts)+1)
+	for i, layout := range layouts {
+		fileName, blockName := ParseTemplateSpec(layout)
+		if i == 0 {
+			entry = blockName
+		}
+		fmt.Fprintf(&src, "{{# include %q #}}\n", fileName+".html")
+		names = append(names, fileName)
+	}
+	fmt.Fprintf(&src, "{{# include %q #}}\n", templateFileName+".html")
+	names = append(names, templateFileName)
+
+	return &tmplr.Template{
+		Name:      strings.Join(names, "+"),
+		RawSource: []byte(src.String()),
+	}, entry
+}
+
+// RenderLayout renders templateFileName inside the given layouts (see WithLayout).
+// Like RenderRequest, the output is buffered and only written on success, and
+// r (which may be nil) supplies the request-scoped template funcs.
+// RenderTemplateFunc is not used for layouts.
+func (app *App[AppContext]) RenderLayout(w http.ResponseWriter, r *http.Request, layouts []string, templateFileName string, view any) error {
+	if len(layouts) == 0 {
+		return app.RenderRequest(w, r, templateFileName, baseFileName(templateFileName), view)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	root, entry := layoutRoot(layouts, templateFileName)
+	if err := app.renderRoot(buf, r, root, root.Name, entry, view); err != nil {
+		return err
+	}
+	return writeBuffered(w, statusCodeOf(view), buf)
+}
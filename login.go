@@ -30,15 +30,16 @@ func (p *SampleLoginPage[AC]) Load(r *http.Request, w http.ResponseWriter, app *
 }
 
 // SampleRegisterPage provides sample registration page functionality.
-// Embed this in your app-specific register page struct.
+// Embed this in your app-specific register page struct and call
+// DecodeForm(r, p) from its Post to bind and validate the form into Errors.
 type SampleRegisterPage[AC any] struct {
 	BasePage
 	CallbackURL    string
 	CsrfToken      string
-	Name           string
-	Email          string
-	Password       string
-	VerifyPassword string
+	Name           string `form:"name" validate:"required,max=100"`
+	Email          string `form:"email" validate:"required,email"`
+	Password       string `form:"password" validate:"required,min=8"`
+	VerifyPassword string `form:"verifyPassword" validate:"required,eqfield=Password"`
 	Errors         map[string]string
 }
 
@@ -2,11 +2,91 @@ package goapplib
 
 import (
 	"net/http"
+	"reflect"
+	"runtime"
 	"strconv"
 )
 
+// WithMixinLoading turns automatic loading of embedded mixins on (the
+// default) or off for a route. Turn it off for views that load their mixins
+// themselves and cannot be loaded twice.
+func WithMixinLoading(enabled bool) Option {
+	return func(o *options) {
+		o.skipMixins = !enabled
+	}
+}
+
+// mixinPaths returns the field index paths of the mixins embedded in a view
+// type, in declaration order. A mixin is an embedded struct whose pointer
+// implements loaderType (the view's Loader[AC]). Only views that are pointers
+// to structs have mixins loaded.
+func mixinPaths(t, loaderType reflect.Type) [][]int {
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return structMixinPaths(t.Elem(), loaderType)
+}
+
+// structMixinPaths returns the mixin paths of struct type t. Embedded structs
+// that are not mixins themselves are searched recursively; pointer and
+// unexported embeds are skipped. If t declares no Load of its own, the
+// embedded struct its Load is promoted from (e.g., an embedded view) is
+// searched instead of loaded, since loading it is loading t.
+func structMixinPaths(t, loaderType reflect.Type) [][]int {
+	var paths [][]int
+	promoted := !declaresLoad(t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.Anonymous || !field.IsExported() || field.Type.Kind() != reflect.Struct {
+			continue
+		}
+		if reflect.PointerTo(field.Type).Implements(loaderType) && !promoted {
+			paths = append(paths, []int{i})
+			continue
+		}
+		for _, sub := range structMixinPaths(field.Type, loaderType) {
+			paths = append(paths, append([]int{i}, sub...))
+		}
+	}
+	return paths
+}
+
+// declaresLoad reports whether struct type t declares a Load method rather
+// than having one promoted from an embedded field. Promoted methods are
+// compiler generated wrappers without a source file.
+func declaresLoad(t reflect.Type) bool {
+	m, ok := reflect.PointerTo(t).MethodByName("Load")
+	if !ok {
+		return false
+	}
+	fn := runtime.FuncForPC(m.Func.Pointer())
+	if fn == nil {
+		return true
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	return file != "<autogenerated>"
+}
+
+// loadMixins loads the embedded mixins of view found for the route, stopping
+// on the first error or finished=true. A view of another type than the one
+// registered (e.g., from a MuxBuilder maker) gets no mixins loaded.
+func loadMixins[AC any](view View[AC], rt *viewRoute, r *http.Request, w http.ResponseWriter, app *App[AC]) (error, bool) {
+	if len(rt.mixins) == 0 || reflect.TypeOf(view) != rt.viewType {
+		return nil, false
+	}
+	v := reflect.ValueOf(view).Elem()
+	for _, path := range rt.mixins {
+		mixin := v.FieldByIndex(path).Addr().Interface().(Loader[AC])
+		if err, finished := mixin.Load(r, w, app); finished || err != nil {
+			return err, finished
+		}
+	}
+	return nil, false
+}
+
 // BasePage provides common page metadata.
-// Embed this in your page structs.
+// Embed this in your page structs. It is plain data and not a mixin: the
+// BasePage layout supplies the default BodyClass.
 type BasePage struct {
 	Title               string // Page title for <title> tag
 	BodyClass           string // CSS classes for <body>
@@ -18,18 +98,11 @@ type BasePage struct {
 	BodyDataAttributes  string // HTML data attributes for body
 }
 
-// Load implements Loader for BasePage.
-// Note: This is a generic implementation that works with any ViewContext.
-func (p *BasePage) Load(r *http.Request, w http.ResponseWriter, vc any) (error, bool) {
-	if p.BodyClass == "" {
-		p.BodyClass = "h-screen flex flex-col bg-gray-50 dark:bg-gray-900 text-gray-900 dark:text-gray-100"
-	}
-	return nil, false
-}
-
 // WithPagination provides pagination support.
-// Embed this in page structs that display paginated lists.
-type WithPagination struct {
+// Embed this in page structs that display paginated lists, instantiated with
+// the view's AppContext (e.g., goapplib.WithPagination[*MyApp]) so it is a
+// Loader[AC] that Register loads before the view.
+type WithPagination[AC any] struct {
 	CurrentPage int   // 0-indexed current page
 	PageSize    int   // Items per page
 	TotalCount  int   // Total number of items
@@ -38,8 +111,8 @@ type WithPagination struct {
 	Pages       []int // Page numbers to display in pagination UI
 }
 
-// Load implements Loader for WithPagination.
-func (p *WithPagination) Load(r *http.Request, w http.ResponseWriter, vc any) (error, bool) {
+// Load implements Loader[AC] for WithPagination.
+func (p *WithPagination[AC]) Load(r *http.Request, w http.ResponseWriter, app *App[AC]) (error, bool) {
 	p.CurrentPage = intQueryParam(r, "page", 0)
 	p.PageSize = intQueryParam(r, "pageSize", 20)
 
@@ -58,7 +131,7 @@ func (p *WithPagination) Load(r *http.Request, w http.ResponseWriter, vc any) (e
 }
 
 // SetTotal updates pagination state based on total count.
-func (p *WithPagination) SetTotal(total int, hasMore bool) {
+func (p *WithPagination[AC]) SetTotal(total int, hasMore bool) {
 	p.TotalCount = total
 	p.HasNextPage = hasMore
 	p.HasPrevPage = p.CurrentPage > 0
@@ -66,7 +139,7 @@ func (p *WithPagination) SetTotal(total int, hasMore bool) {
 }
 
 // EvalPages calculates page numbers to display.
-func (p *WithPagination) EvalPages() {
+func (p *WithPagination[AC]) EvalPages() {
 	p.Pages = nil
 	if p.TotalCount <= 0 || p.PageSize <= 0 {
 		return
@@ -97,12 +170,12 @@ func (p *WithPagination) EvalPages() {
 }
 
 // Offset returns the offset for database queries.
-func (p *WithPagination) Offset() int {
+func (p *WithPagination[AC]) Offset() int {
 	return p.CurrentPage * p.PageSize
 }
 
 // PrevPage returns the previous page number.
-func (p *WithPagination) PrevPage() int {
+func (p *WithPagination[AC]) PrevPage() int {
 	if p.CurrentPage > 0 {
 		return p.CurrentPage - 1
 	}
@@ -110,27 +183,27 @@ func (p *WithPagination) PrevPage() int {
 }
 
 // NextPage returns the next page number.
-func (p *WithPagination) NextPage() int {
+func (p *WithPagination[AC]) NextPage() int {
 	return p.CurrentPage + 1
 }
 
 // Paginator returns self for template access via .Paginator
 // This allows templates to use {{ .Paginator.HasPrevPage }} etc.
 // when the page struct embeds WithPagination.
-func (p *WithPagination) Paginator() *WithPagination {
+func (p *WithPagination[AC]) Paginator() *WithPagination[AC] {
 	return p
 }
 
 // WithFiltering provides search and sort support.
-// Embed this in page structs that support filtering.
-type WithFiltering struct {
+// Embed this in page structs that support filtering, like WithPagination.
+type WithFiltering[AC any] struct {
 	Query    string // Search query
 	Sort     string // Sort field/direction
 	ViewMode string // Display mode: "grid", "table", etc.
 }
 
-// Load implements Loader for WithFiltering.
-func (p *WithFiltering) Load(r *http.Request, w http.ResponseWriter, vc any) (error, bool) {
+// Load implements Loader[AC] for WithFiltering.
+func (p *WithFiltering[AC]) Load(r *http.Request, w http.ResponseWriter, app *App[AC]) (error, bool) {
 	q := r.URL.Query()
 	p.Query = q.Get("q")
 	p.Sort = q.Get("sort")
@@ -148,8 +221,8 @@ func (p *WithFiltering) Load(r *http.Request, w http.ResponseWriter, vc any) (er
 }
 
 // WithAuth provides authentication info.
-// Embed this in page structs that need user info.
-type WithAuth struct {
+// Embed this in page structs that need user info, like WithPagination.
+type WithAuth[AC any] struct {
 	LoggedInUserId string // Current user's ID
 	Username       string // Current user's display name
 	IsLoggedIn     bool   // True if user is authenticated
@@ -158,7 +231,7 @@ type WithAuth struct {
 
 // Load is a no-op for WithAuth.
 // Use LoadWithAuth to load auth info with your auth services.
-func (p *WithAuth) Load(r *http.Request, w http.ResponseWriter, vc any) (error, bool) {
+func (p *WithAuth[AC]) Load(r *http.Request, w http.ResponseWriter, app *App[AC]) (error, bool) {
 	// This is a no-op. Use AuthLoader or LoadWithAuth instead.
 	return nil, false
 }
@@ -175,7 +248,7 @@ type AuthUser interface {
 }
 
 // LoadWithAuth loads auth info using the provided auth services.
-func (p *WithAuth) LoadWithAuth(r *http.Request, provider AuthProvider) (error, bool) {
+func (p *WithAuth[AC]) LoadWithAuth(r *http.Request, provider AuthProvider) (error, bool) {
 	p.LoggedInUserId = provider.GetLoggedInUserId(r)
 	p.IsLoggedIn = p.LoggedInUserId != ""
 
@@ -194,15 +267,16 @@ func (p *WithAuth) LoadWithAuth(r *http.Request, provider AuthProvider) (error,
 
 // AuthLoader returns a LoaderFunc that loads auth info.
 // Use this with LoadAll when you have an AuthProvider.
-func AuthLoader[AC any](auth *WithAuth, provider AuthProvider) LoaderFunc[AC] {
+func AuthLoader[AC any](auth *WithAuth[AC], provider AuthProvider) LoaderFunc[AC] {
 	return func(r *http.Request, w http.ResponseWriter, app *App[AC]) (error, bool) {
 		return auth.LoadWithAuth(r, provider)
 	}
 }
 
 // WithHtmx provides HTMX request detection.
-// Embed this in page structs that need HTMX-aware rendering.
-type WithHtmx struct {
+// Embed this in page structs that need HTMX-aware rendering, like
+// WithPagination.
+type WithHtmx[AC any] struct {
 	IsHtmx      bool   // True if this is an HTMX request
 	IsBoosted   bool   // True if this is a boosted link
 	Target      string // HX-Target header value
@@ -212,8 +286,8 @@ type WithHtmx struct {
 	Prompt      string // HX-Prompt header value
 }
 
-// Load implements Loader for WithHtmx.
-func (p *WithHtmx) Load(r *http.Request, w http.ResponseWriter, vc any) (error, bool) {
+// Load implements Loader[AC] for WithHtmx.
+func (p *WithHtmx[AC]) Load(r *http.Request, w http.ResponseWriter, app *App[AC]) (error, bool) {
 	p.IsHtmx = r.Header.Get("HX-Request") == "true"
 	p.IsBoosted = r.Header.Get("HX-Boosted") == "true"
 	p.Target = r.Header.Get("HX-Target")
@@ -225,7 +299,7 @@ func (p *WithHtmx) Load(r *http.Request, w http.ResponseWriter, vc any) (error,
 }
 
 // ShouldRenderFragment returns true if only a fragment should be rendered.
-func (p *WithHtmx) ShouldRenderFragment() bool {
+func (p *WithHtmx[AC]) ShouldRenderFragment() bool {
 	return p.IsHtmx && !p.IsBoosted
 }
 
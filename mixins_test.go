@@ -0,0 +1,109 @@
+package goapplib
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type traceKey struct{}
+
+// trace records that name was loaded for the request.
+func trace(r *http.Request, name string) {
+	if log, ok := r.Context().Value(traceKey{}).(*[]string); ok {
+		*log = append(*log, name)
+	}
+}
+
+type FirstMixin struct{}
+
+func (m *FirstMixin) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	trace(r, "first")
+	return nil, false
+}
+
+type SecondMixin struct{}
+
+func (m *SecondMixin) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	trace(r, "second")
+	return nil, false
+}
+
+// InnerPage is a view embedded by other views. It finishes the response
+// when asked to, so the tests need no templates.
+type InnerPage struct {
+	SecondMixin
+}
+
+func (p *InnerPage) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	trace(r, "inner")
+	return nil, r.URL.Query().Has("finish")
+}
+
+// mixinPage embeds mixins, a view and a built-in mixin.
+type mixinPage struct {
+	FirstMixin
+	InnerPage
+	WithHtmx[struct{}]
+	secondMixin SecondMixin
+}
+
+func (p *mixinPage) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	if p.IsHtmx {
+		trace(r, "page htmx")
+	} else {
+		trace(r, "page")
+	}
+	return nil, true
+}
+
+// aliasPage gets its Load promoted from the embedded InnerPage.
+type aliasPage struct {
+	InnerPage
+}
+
+func serveTraced(handler http.Handler, target string, header http.Header) []string {
+	var log []string
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r = r.WithContext(context.WithValue(r.Context(), traceKey{}, &log))
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return log
+}
+
+// TestMixinsLoadInOrder checks that embedded Loader[AC] mixins load in
+// declaration order before the view's Load.
+func TestMixinsLoadInOrder(t *testing.T) {
+	mux := Register[*mixinPage](NewApp(struct{}{}, nil), nil, "/page")
+	got := serveTraced(mux, "/page", http.Header{"Hx-Request": {"true"}})
+	want := []string{"first", "inner", "page htmx"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded %q, want %q", got, want)
+	}
+}
+
+// TestMixinsOfPromotedLoad checks that an embedded view whose Load is the
+// view's own is not loaded twice, while its mixins still are.
+func TestMixinsOfPromotedLoad(t *testing.T) {
+	mux := Register[*aliasPage](NewApp(struct{}{}, nil), nil, "/alias")
+	got := serveTraced(mux, "/alias?finish", nil)
+	want := []string{"second", "inner"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded %q, want %q", got, want)
+	}
+}
+
+// TestMixinLoadingDisabled checks that WithMixinLoading(false) leaves the
+// mixins to the view.
+func TestMixinLoadingDisabled(t *testing.T) {
+	mux := Register[*mixinPage](NewApp(struct{}{}, nil), nil, "/page", WithMixinLoading(false))
+	got := serveTraced(mux, "/page", nil)
+	want := []string{"page"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded %q, want %q", got, want)
+	}
+}
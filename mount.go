@@ -0,0 +1,150 @@
+package goapplib
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	tmplr "github.com/panyam/templar"
+)
+
+// appNode is the part of an App used across Mount, whatever its AppContext.
+type appNode interface {
+	RouteLister
+	Validate() error
+	findURL(name string, params []any) (string, bool, error)
+	templateGroup() *tmplr.TemplateGroup
+	rootApp() appNode
+}
+
+// mountPoint links an App mounted with Mount to its parent.
+type mountPoint struct {
+	parent appNode
+	prefix string   // Full path prefix, including the parent's mount and groups
+	opts   []Option // Options of the parent's enclosing groups and of Mount
+
+	templatesMu     sync.Mutex
+	own, parentTmpl *tmplr.TemplateGroup // Groups templates was chained from
+	templates       *tmplr.TemplateGroup
+}
+
+// mountedApp is an App mounted on its parent.
+type mountedApp struct {
+	name string // Prefix of its route names in the parent, may be empty
+	app  appNode
+	at   int // Number of parent routes registered before it, for ordering
+}
+
+// Mount serves the routes of child, an App with its own AppContext, under
+// prefix of parent's mux. group registers the child's routes on the child,
+// like a PageGroup given to RegisterGroup:
+//
+//	billing := goapplib.NewApp(billingCtx, billingTemplates)
+//	goapplib.Mount(app, rootMux, "/billing", billing, &BillingRoutes{},
+//	    goapplib.WithName("billing"), goapplib.WithLayout("BasePage"),
+//	    goapplib.WithMiddleware(requireLogin))
+//
+// Child views render with the child's request context and error pages.
+// Templates are looked up in the child's templates first and then in the
+// parent's, so the child can use the parent's layouts. Options act as for
+// RegisterGroup: layouts and other view defaults, including those of the
+// parent's enclosing groups, apply to the child's views; WithMiddleware
+// wraps the whole child; WithName prefixes the names of the child's routes
+// as seen from the parent. URLFor works across the mount point in both
+// directions, and parent.Routes lists the child's routes.
+//
+// A child can only be mounted once, and all its routes must be registered
+// by group. Use MountOn to mount on a MuxBuilder.
+func Mount[A, B any](
+	parent *App[A],
+	mux *http.ServeMux,
+	prefix string,
+	child *App[B],
+	group PageGroup[B],
+	opts ...Option,
+) *http.ServeMux {
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	mount(parent, mux, parent.groupPrefix(), prefix, child, group, opts)
+	return mux
+}
+
+// MountOn mounts child under prefix of b, like Mount. The child is wrapped
+// in the middleware b uses at this point (see MuxBuilder.Use), and b's
+// layouts apply to the child's views.
+//
+//	b := app.NewMux().Use(logRequests).Layout("BasePage")
+//	goapplib.MountOn(b, "/billing", billing, &BillingRoutes{}, goapplib.WithName("billing"))
+func MountOn[A, B any](b *MuxBuilder[A], prefix string, child *App[B], group PageGroup[B], opts ...Option) *MuxBuilder[A] {
+	builderOpts := append([]Option{}, b.defaults...)
+	if len(b.middleware) > 0 {
+		builderOpts = append(builderOpts, WithMiddleware(b.middleware...))
+	}
+	mount(b.app, b.mux, b.prefix, prefix, child, group, append(builderOpts, opts...))
+	return b
+}
+
+// mount mounts child under prefix of mux, itself mounted at base.
+func mount[A, B any](parent *App[A], mux *http.ServeMux, base, prefix string, child *App[B], group PageGroup[B], opts []Option) {
+	if child.mount != nil {
+		panic(fmt.Sprintf("goapplib: app is already mounted at %q", child.mount.prefix))
+	}
+
+	// Name and middleware of the mount itself, as for RegisterGroup
+	o := parent.newOptions(opts)
+
+	var inherited []Option
+	if parent.mount != nil {
+		inherited = append(inherited, parent.mount.opts...)
+	}
+	for _, frame := range parent.groups {
+		inherited = append(inherited, frame.opts...)
+	}
+	child.mount = &mountPoint{
+		parent: parent,
+		prefix: strings.TrimSuffix(joinPattern(base, prefix), "/"),
+		opts:   append(inherited, opts...),
+	}
+	childMux := group.RegisterRoutes(child)
+
+	parent.routesMu.Lock()
+	parent.mounts = append(parent.mounts, mountedApp{name: o.name, app: child, at: len(parent.routes)})
+	parent.routesMu.Unlock()
+
+	// Mount with StripPrefix, like RegisterGroup
+	mountPattern := prefix
+	if len(prefix) > 0 && prefix[len(prefix)-1] != '/' {
+		mountPattern = prefix + "/"
+	}
+	mux.Handle(mountPattern, withMiddleware(http.StripPrefix(prefix, childMux), o.middleware))
+}
+
+// chainTemplates returns a TemplateGroup that loads templates from own and
+// then from parent, with the funcs of both (own's win). It is rebuilt when
+// either group changes, e.g. on reload.
+func (m *mountPoint) chainTemplates(own, parent *tmplr.TemplateGroup) *tmplr.TemplateGroup {
+	m.templatesMu.Lock()
+	defer m.templatesMu.Unlock()
+	if m.templates == nil || m.own != own || m.parentTmpl != parent {
+		loader := &tmplr.LoaderList{}
+		loader.AddLoader(own.Loader)
+		loader.AddLoader(parent.Loader)
+
+		templates := tmplr.NewTemplateGroup()
+		templates.Loader = loader
+		templates.AddFuncs(parent.Funcs)
+		templates.AddFuncs(own.Funcs)
+		m.own, m.parentTmpl, m.templates = own, parent, templates
+	}
+	return m.templates
+}
+
+// rootApp returns the outermost App app is mounted in, or app itself.
+func (app *App[AppContext]) rootApp() appNode {
+	if app.mount != nil {
+		return app.mount.parent.rootApp()
+	}
+	return app
+}
@@ -0,0 +1,69 @@
+package goapplib
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// invoicePage is a view of a mounted app with its own AppContext.
+type invoicePage struct{}
+
+func (p *invoicePage) Load(r *http.Request, w http.ResponseWriter, app *App[int]) (error, bool) {
+	return nil, false
+}
+
+type billingRoutes struct{}
+
+func (g *billingRoutes) RegisterRoutes(app *App[int]) *http.ServeMux {
+	return Register[*invoicePage](app, nil, "/invoices/{id}", WithName("invoice"))
+}
+
+func TestURLForAcrossMount(t *testing.T) {
+	parent := newURLApp()
+	billing := NewApp(0, nil)
+	Mount(parent, nil, "/billing", billing, &billingRoutes{}, WithName("billing"))
+
+	tests := []struct {
+		from interface {
+			URLFor(string, ...any) (string, error)
+		}
+		name   string
+		params []any
+		want   string
+	}{
+		{parent, "billing.invoice", []any{"id", 7}, "/billing/invoices/7"},
+		{parent, "billing.invoice", []any{"id", 7, "download", true}, "/billing/invoices/7?download=true"},
+		{billing, "invoice", []any{"id", 7}, "/billing/invoices/7"},
+		{billing, "games.edit", []any{"id", 42}, "/games/42/edit"},
+		{billing, "admin.user", []any{"id", 1}, "/admin/users/1"},
+	}
+	for _, tt := range tests {
+		if got, err := tt.from.URLFor(tt.name, tt.params...); err != nil || got != tt.want {
+			t.Errorf("URLFor(%q, %v) = %q, %v; want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+
+	if _, err := parent.URLFor("invoice", "id", 7); err == nil {
+		t.Error("the parent found a child route without the mount name")
+	}
+	if _, err := parent.URLFor("billing.invoice"); err == nil || !strings.Contains(err.Error(), "missing params id") {
+		t.Errorf("got %v, want the missing param", err)
+	}
+}
+
+func TestURLForMountInGroup(t *testing.T) {
+	parent := NewApp(struct{}{}, nil)
+	billing := NewApp(0, nil)
+	parent.NewMux().Group("/orgs/{org}", func(b *MuxBuilder[struct{}]) {
+		MountOn(b, "/billing", billing, &billingRoutes{}, WithName("billing"))
+	})
+
+	want := "/orgs/acme/billing/invoices/7"
+	if got, err := parent.URLFor("billing.invoice", "org", "acme", "id", 7); err != nil || got != want {
+		t.Errorf("from the parent: got %q, %v; want %q", got, err, want)
+	}
+	if got, err := billing.URLFor("invoice", "org", "acme", "id", 7); err != nil || got != want {
+		t.Errorf("from the child: got %q, %v; want %q", got, err, want)
+	}
+}
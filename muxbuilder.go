@@ -1,63 +1,91 @@
 package goapplib
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
 )
 
 // MuxBuilder provides a fluent API for building routes.
 type MuxBuilder[AC any] struct {
-	app *App[AC]
-	mux *http.ServeMux
+	app      *App[AC]
+	mux      *http.ServeMux
+	prefix   string   // Mount prefix of the builder's routes, for URLFor
+	defaults []Option // Applied before each Page's own options
+
+	// Middleware wrapping routes registered from now on, outermost first
+	middleware []func(http.Handler) http.Handler
 }
 
-// Page registers a View-based page.
+// Page registers a View-based page, like Register: the template defaults to
+// the file named after the view type, and the view is served with the same
+// error handling, hooks and middleware.
 func (b *MuxBuilder[AC]) Page(pattern string, maker func() View[AC], opts ...Option) *MuxBuilder[AC] {
-	// Apply options
-	o := &options{}
-	for _, opt := range opts {
-		opt(o)
-	}
+	o := b.app.newOptions(append(append([]Option{}, b.defaults...), opts...))
+	sample := maker()
+	rt := o.newViewRoute(o.viewTemplate(typeNameFromValue(sample)), reflect.TypeOf(sample), reflect.TypeFor[Loader[AC]](), b.app.EnableJSON)
+	return b.page(pattern, maker, sample, o, rt)
+}
 
-	// Determine template name from maker's return type
-	templateFileName := o.templateFileName
-	if templateFileName == "" {
-		// Get type name from a sample instance
-		sample := maker()
-		templateFileName = typeNameFromValue(sample)
+// SmartPage registers a View that renders as a full page or as a fragment
+// for HTMX requests, like SmartRegister. The view must implement HtmxAware.
+func (b *MuxBuilder[AC]) SmartPage(pattern string, maker func() View[AC], fullTemplateSpec string, fragmentTemplateSpec string, opts ...Option) *MuxBuilder[AC] {
+	o := b.app.newOptions(append(append([]Option{}, b.defaults...), opts...))
+	sample := maker()
+	if _, ok := sample.(HtmxAware); !ok {
+		panic(fmt.Sprintf("goapplib: SmartPage %q: %T does not implement HtmxAware", pattern, sample))
 	}
-	templateBlockName := o.templateBlockName
-
-	// Create handler
-	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		view := maker()
-
-		err, finished := view.Load(r, w, b.app)
-		if finished {
-			return
-		}
+	fullFileName, fullBlockName := ParseTemplateSpec(fullTemplateSpec)
+	fragFileName, fragBlockName := ParseTemplateSpec(fragmentTemplateSpec)
+	rt := o.newViewRoute(TemplateRef{File: fullFileName, Block: fullBlockName}, reflect.TypeOf(sample), reflect.TypeFor[Loader[AC]](), b.app.EnableJSON)
+	rt.fragment = &TemplateRef{File: fragFileName, Block: fragBlockName}
+	return b.page(pattern, maker, sample, o, rt)
+}
 
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+// page records and registers a view route built by Page or SmartPage.
+func (b *MuxBuilder[AC]) page(pattern string, maker func() View[AC], sample View[AC], o *options, rt *viewRoute) *MuxBuilder[AC] {
+	info := b.app.newRouteInfo(b.prefix, pattern, append(append([]func(http.Handler) http.Handler{}, b.middleware...), o.middleware...))
+	info.Name, info.ViewType, info.Templates = o.name, typeNameFromValue(sample), rt.templateRefs()
+	info.targets = rt.renderTargets()
+	if info.Methods == nil {
+		info.Methods = viewMethods[AC](sample)
+	}
+	b.app.addRoute(info)
 
-		b.app.RenderTemplate(w, templateFileName, templateBlockName, view)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveView(b.app, rt, maker(), w, r)
 	})
+	b.handle(pattern, withMiddleware(handler, o.middleware))
+	return b
+}
 
-	// Apply middleware
-	for i := len(o.middleware) - 1; i >= 0; i-- {
-		handler = o.middleware[i](handler)
-	}
+// handle registers h wrapped in the builder's current middleware stack.
+func (b *MuxBuilder[AC]) handle(pattern string, h http.Handler) {
+	b.mux.Handle(pattern, withMiddleware(h, b.middleware))
+}
 
-	b.mux.Handle(pattern, handler)
+// addHandlerRoute records a non-view route for App.Routes.
+func (b *MuxBuilder[AC]) addHandlerRoute(pattern string) {
+	b.app.addRoute(b.app.newRouteInfo(b.prefix, pattern, b.middleware))
+}
+
+// Layout sets the default layout(s) for pages registered afterwards on this
+// builder and its groups (see WithLayout).
+func (b *MuxBuilder[AC]) Layout(layouts ...string) *MuxBuilder[AC] {
+	b.defaults = append(b.defaults, WithLayout(layouts...))
 	return b
 }
 
 // Group creates a nested group with a prefix.
+// The group starts with the builder's layouts and middleware; what it adds
+// with Layout and Use only applies inside the group.
 func (b *MuxBuilder[AC]) Group(prefix string, setup func(*MuxBuilder[AC])) *MuxBuilder[AC] {
 	subBuilder := &MuxBuilder[AC]{
-		app: b.app,
-		mux: http.NewServeMux(),
+		app:        b.app,
+		mux:        http.NewServeMux(),
+		prefix:     joinPattern(b.prefix, prefix),
+		defaults:   append([]Option{}, b.defaults...),
+		middleware: append([]func(http.Handler) http.Handler{}, b.middleware...),
 	}
 
 	setup(subBuilder)
@@ -74,32 +102,41 @@ func (b *MuxBuilder[AC]) Group(prefix string, setup func(*MuxBuilder[AC])) *MuxB
 
 // Handler registers an http.Handler.
 func (b *MuxBuilder[AC]) Handler(pattern string, h http.Handler) *MuxBuilder[AC] {
-	b.mux.Handle(pattern, h)
+	b.addHandlerRoute(pattern)
+	b.handle(pattern, h)
 	return b
 }
 
 // HandleFunc registers an http.HandlerFunc.
 func (b *MuxBuilder[AC]) HandleFunc(pattern string, h http.HandlerFunc) *MuxBuilder[AC] {
-	b.mux.HandleFunc(pattern, h)
+	b.addHandlerRoute(pattern)
+	b.handle(pattern, h)
 	return b
 }
 
 // Static registers a static file server.
 func (b *MuxBuilder[AC]) Static(pattern string, dir string) *MuxBuilder[AC] {
-	b.mux.Handle(pattern, http.StripPrefix(pattern, http.FileServer(http.Dir(dir))))
+	b.addHandlerRoute(pattern)
+	b.handle(pattern, http.StripPrefix(pattern, http.FileServer(http.Dir(dir))))
 	return b
 }
 
-// Use adds middleware to all subsequent routes.
-// Note: This only affects routes registered after this call.
-func (b *MuxBuilder[AC]) Use(mw func(http.Handler) http.Handler) *MuxBuilder[AC] {
-	// Wrap the entire mux
-	// This is a simplified approach - for more complex middleware needs,
-	// consider using a dedicated router library
+// Use adds middleware to every route registered afterwards on this builder
+// and its groups. Middleware added earlier wraps middleware added later, and
+// both wrap a Page's own WithMiddleware:
+//
+//	m := app.NewMux().Use(logRequests)
+//	m.Group("/admin", func(m *goapplib.MuxBuilder[*AC]) {
+//	    m.Use(requireAdmin, csrf)
+//	    m.Page("/users", ...) // logRequests -> requireAdmin -> csrf -> page
+//	})
+func (b *MuxBuilder[AC]) Use(mw ...func(http.Handler) http.Handler) *MuxBuilder[AC] {
+	b.middleware = append(b.middleware, mw...)
 	return b
 }
 
-// Build returns the constructed ServeMux.
+// Build returns the constructed ServeMux. Routes already carry their
+// middleware, so it can be served as is.
 func (b *MuxBuilder[AC]) Build() *http.ServeMux {
 	return b.mux
 }
@@ -109,10 +146,15 @@ func (b *MuxBuilder[AC]) Mux() *http.ServeMux {
 	return b.mux
 }
 
-// typeNameFromValue extracts the type name from a value.
+// typeNameFromValue returns the type name of v without package prefix,
+// dereferencing pointers (e.g., "GameListingPage" for a *GameListingPage).
 func typeNameFromValue(v any) string {
-	if v == nil {
+	typ := reflect.TypeOf(v)
+	if typ == nil {
 		return ""
 	}
-	return typeNameOf[any]()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Name()
 }
@@ -0,0 +1,84 @@
+package goapplib
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// JSONView is implemented by views that control their JSON representation.
+// The returned value is marshaled instead of the view itself.
+type JSONView interface {
+	JSONData() any
+}
+
+// WantsJSON returns true if the request asks for a JSON response, either with
+// ?format=json or an Accept header that prefers application/json over HTML.
+func WantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// RenderJSON writes view as JSON with the status chosen by the view (see StatusCoder).
+// Views implementing JSONView supply their own data. Otherwise only the view's
+// own exported fields are included: embedded structs (BasePage, WithPagination,
+// ...) are skipped unless they have an explicit json tag, and `json:"-"`
+// fields are skipped as usual.
+func (app *App[AppContext]) RenderJSON(w http.ResponseWriter, view any) error {
+	var data any
+	if jv, ok := view.(JSONView); ok {
+		data = jv.JSONData()
+	} else {
+		data = viewJSONFields(view)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(data); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return writeBuffered(w, statusCodeOf(view), buf)
+}
+
+// viewJSONFields returns the exported, non-embedded fields of a view struct
+// keyed by their JSON names. Non-struct values are returned as is.
+func viewJSONFields(view any) any {
+	v := reflect.ValueOf(view)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return view
+	}
+
+	out := map[string]any{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("json")
+		if !field.IsExported() || (field.Anonymous && !hasTag) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fv := v.Field(i)
+		if strings.Contains(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+		out[name] = fv.Interface()
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package goapplib
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Redirect is returned as the error from View.Load or an action to send the
+// client elsewhere, instead of calling http.Redirect and returning
+// finished=true:
+//
+//	if !p.IsLoggedIn {
+//	    return goapplib.RedirectTo("/login"), false
+//	}
+//
+// Plain requests get a 3xx response. HTMX requests get HX-Redirect (or
+// HX-Location if Location is set), since htmx would otherwise follow the
+// redirect itself and swap the new page into the target element.
+type Redirect struct {
+	URL      string
+	Status   int  // Defaults to 303 See Other
+	Location bool // For htmx, navigate with HX-Location (no full page reload)
+}
+
+// RedirectTo returns a 303 Redirect to url.
+func RedirectTo(url string) *Redirect {
+	return &Redirect{URL: url}
+}
+
+func (rd *Redirect) Error() string {
+	return "redirect to " + rd.URL
+}
+
+// ServeHTTP writes the redirect for r.
+func (rd *Redirect) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if IsHtmxRequest(r) {
+		if rd.Location {
+			NewHtmxResponse(w).Location(rd.URL)
+		} else {
+			NewHtmxResponse(w).Redirect(rd.URL)
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	status := rd.Status
+	if status == 0 {
+		status = http.StatusSeeOther
+	}
+	http.Redirect(w, r, rd.URL, status)
+}
+
+// asRedirect returns the Redirect in err's chain, if any.
+func asRedirect(err error) (*Redirect, bool) {
+	var rd *Redirect
+	ok := errors.As(err, &rd)
+	return rd, ok
+}
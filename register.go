@@ -2,10 +2,12 @@ package goapplib
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // Option configures registration behavior.
@@ -14,7 +16,24 @@ type Option func(*options)
 type options struct {
 	templateFileName  string
 	templateBlockName string
+	templateBlockSet  bool // The WithTemplate spec named a block, maybe ""
 	middleware        []func(http.Handler) http.Handler
+	json              *bool
+	layouts           []string
+	stream            bool
+	streamHead        string
+	streamBody        string
+	timeout           time.Duration
+	skipMixins        bool
+	name              string
+}
+
+// jsonEnabled reports whether JSON responses are allowed for this route.
+func (o *options) jsonEnabled(appDefault bool) bool {
+	if o.json != nil {
+		return *o.json
+	}
+	return appDefault
 }
 
 // WithTemplate sets the template file and optional block name.
@@ -32,6 +51,7 @@ type options struct {
 func WithTemplate(spec string) Option {
 	return func(o *options) {
 		o.templateFileName, o.templateBlockName = ParseTemplateSpec(spec)
+		o.templateBlockSet = strings.Contains(spec, ":")
 	}
 }
 
@@ -68,6 +88,282 @@ func WithMiddleware(mw ...func(http.Handler) http.Handler) Option {
 	}
 }
 
+// WithJSON enables or disables JSON responses for this route,
+// overriding App.EnableJSON.
+func WithJSON(enabled bool) Option {
+	return func(o *options) {
+		o.json = &enabled
+	}
+}
+
+// WithName names a route so its URL can be built with App.URLFor and the
+// urlFor template func. Given to RegisterGroup, it prefixes the names of the
+// group's routes: a route named "edit" in a group named "games" is
+// "games.edit".
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// newOptions applies the options inherited through Mount and those of the
+// enclosing groups (see RegisterGroup), followed by opts. Group middleware is
+// not inherited here since it already wraps the whole group mux, and group
+// names become prefixes of the route name.
+func (app *App[AppContext]) newOptions(opts []Option) *options {
+	o := &options{}
+	if app.mount != nil {
+		for _, opt := range app.mount.opts {
+			opt(o)
+		}
+		o.name = "" // Mount names only apply when seen from the parent
+	}
+	var names []string
+	for _, group := range app.groups {
+		for _, opt := range group.opts {
+			opt(o)
+		}
+		if o.name != "" {
+			names = append(names, o.name)
+			o.name = ""
+		}
+	}
+	o.middleware = nil
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.name != "" && len(names) > 0 {
+		o.name = strings.Join(append(names, o.name), ".")
+	}
+	return o
+}
+
+// groupPrefix returns the combined mount prefix of the app (see Mount) and
+// the groups being registered.
+func (app *App[AppContext]) groupPrefix() string {
+	var prefix string
+	if app.mount != nil {
+		prefix = app.mount.prefix
+	}
+	for _, group := range app.groups {
+		prefix += strings.TrimSuffix(group.prefix, "/")
+	}
+	return prefix
+}
+
+// viewTemplate returns the template of a view named typeName: the one set
+// with WithTemplate, or the file named after the view type. Without an
+// explicit block, the block is derived from the base file name.
+func (o *options) viewTemplate(typeName string) TemplateRef {
+	ref := TemplateRef{File: o.templateFileName, Block: o.templateBlockName}
+	if ref.File == "" {
+		ref.File = typeName
+	}
+	if ref.Block == "" && !o.templateBlockSet {
+		ref.Block = baseFileName(ref.File)
+	}
+	return ref
+}
+
+// viewRoute is the resolved rendering configuration of a registered view.
+type viewRoute struct {
+	full       TemplateRef
+	fragment   *TemplateRef // Set by SmartRegister for HTMX fragment requests
+	layouts    []string
+	json       bool
+	stream     bool
+	streamHead string
+	streamBody string
+	timeout    time.Duration
+	viewType   reflect.Type
+	mixins     [][]int // Field paths of the mixins embedded in viewType, loaded before Load
+}
+
+// newViewRoute creates the route configuration for the given options.
+// viewType is the type of the registered view and loaderType its
+// Loader[AC], used to find its mixins.
+func (o *options) newViewRoute(full TemplateRef, viewType, loaderType reflect.Type, appJSON bool) *viewRoute {
+	rt := &viewRoute{
+		full:       full,
+		layouts:    o.layouts,
+		json:       o.jsonEnabled(appJSON),
+		stream:     o.stream,
+		streamHead: o.streamHead,
+		streamBody: o.streamBody,
+		timeout:    o.timeout,
+		viewType:   viewType,
+	}
+	if !o.skipMixins {
+		rt.mixins = mixinPaths(viewType, loaderType)
+	}
+	return rt
+}
+
+// renderTargets returns the roots and blocks this route renders, as built by
+// serveView and serveStreaming.
+func (rt *viewRoute) renderTargets() []renderTarget {
+	full := renderTarget{layouts: rt.layouts, file: rt.full.File, blocks: []string{rt.full.Block}}
+	if len(rt.layouts) > 0 {
+		_, entry := layoutRoot(rt.layouts, rt.full.File)
+		full.blocks = []string{entry}
+	}
+	if rt.stream {
+		full.blocks = append(full.blocks, rt.streamHead, rt.streamBody)
+	}
+	targets := []renderTarget{full}
+	if rt.fragment != nil {
+		targets = append(targets, renderTarget{file: rt.fragment.File, blocks: []string{rt.fragment.Block}})
+	}
+	return targets
+}
+
+// templateRefs returns the templates this route may render.
+func (rt *viewRoute) templateRefs() []TemplateRef {
+	var refs []TemplateRef
+	for i, layout := range rt.layouts {
+		fileName, blockName := ParseTemplateSpec(layout)
+		if i > 0 {
+			blockName = "" // Only the outermost layout's block is executed
+		}
+		refs = append(refs, TemplateRef{File: fileName, Block: blockName})
+	}
+	if len(rt.layouts) > 0 {
+		refs = append(refs, TemplateRef{File: rt.full.File})
+	} else {
+		refs = append(refs, rt.full)
+	}
+	if rt.fragment != nil {
+		refs = append(refs, *rt.fragment)
+	}
+	if rt.stream {
+		streamFile := rt.full.File
+		if len(rt.layouts) > 0 {
+			streamFile, _ = ParseTemplateSpec(rt.layouts[0])
+		}
+		refs = append(refs, TemplateRef{File: streamFile, Block: rt.streamHead}, TemplateRef{File: streamFile, Block: rt.streamBody})
+	}
+	return refs
+}
+
+// serveView loads view (after its embedded mixins) and renders it as JSON,
+// a fragment or a full page, calling the view's render hooks (see
+// BeforeRenderer) around the render.
+// Non-GET requests to views with actions (Poster, Putter, ...) run the action
+// after Load and redirect on success (Post/Redirect/Get).
+func serveView[AC any](app *App[AC], rt *viewRoute, view View[AC], w http.ResponseWriter, r *http.Request) {
+	// Reading _method consumes the form body, so only views with actions,
+	// which expect a form, get the override
+	if _, hasActions := actionFor(view, ""); hasActions {
+		applyMethodOverride(r)
+	}
+	r = withFlashState(r)
+	// Streamed pages are sent as they render, so they are closed afterwards
+	streaming := rt.stream && canStream(r)
+	w, r, done, err := app.beginRequest(w, r, !streaming)
+	if err != nil {
+		app.HandleError(w, r, err)
+		return
+	}
+	defer done()
+
+	if streaming {
+		serveStreaming(app, rt, view, w, r)
+		return
+	}
+
+	// Load embedded mixins and then the view - pass the whole app
+	err, finished, timedOut := loadView(app, rt, view, w, r)
+	if timedOut {
+		app.RenderTimeout(w, r)
+		return
+	}
+	if finished {
+		return
+	}
+
+	if err != nil {
+		app.HandleError(w, r, err)
+		return
+	}
+
+	// Non-GET methods go to the view's action, if it has any
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		if action, hasActions := actionFor(view, r.Method); hasActions {
+			if action == nil {
+				w.Header().Set("Allow", strings.Join(allowedMethods(view), ", "))
+				app.RenderError(w, r, http.StatusMethodNotAllowed, "")
+				return
+			}
+			if rerender := runAction(app, view, action, w, r); !rerender {
+				return
+			}
+			// Validation failed: render the view again with its errors.
+			// HTMX only swaps 2xx responses, so keep 200 for it.
+			if !IsHtmxRequest(r) {
+				w = &statusWriter{ResponseWriter: w, status: http.StatusUnprocessableEntity}
+			}
+		}
+	}
+
+	if w, err = beforeRender(view, w, r); err != nil {
+		app.HandleError(w, r, err)
+		return
+	}
+
+	if rt.json {
+		w.Header().Add("Vary", "Accept")
+		if WantsJSON(r) {
+			jsonErr := app.RenderJSON(w, view)
+			if jsonErr != nil {
+				app.HandleError(w, r, jsonErr)
+			}
+			afterRender(view, r, jsonErr)
+			return
+		}
+	}
+
+	// Render template (buffered, so a failure leaves the response untouched
+	// and the flashes queued)
+	fw := app.takeFlashes(w, r)
+	tmpl := rt.full
+	useLayout := len(rt.layouts) > 0
+	if ha, ok := view.(HtmxAware); ok && rt.fragment != nil && ha.ShouldRenderFragment() {
+		tmpl, useLayout = *rt.fragment, false
+	}
+	if to, ok := view.(TemplateOverrider); ok {
+		if spec := to.TemplateFor(r); spec != "" {
+			tmpl.File, tmpl.Block = ParseTemplateSpec(spec)
+		}
+	}
+	handled, renderErr := app.renderCached(fw, r, view, tmpl.File+":"+tmpl.Block, func(out io.Writer) error {
+		if useLayout {
+			root, entry := layoutRoot(rt.layouts, tmpl.File)
+			return app.renderRoot(out, r, root, root.Name, entry, view)
+		}
+		return app.renderTo(out, r, tmpl.File, tmpl.Block, view)
+	})
+	if !handled {
+		if useLayout {
+			renderErr = app.RenderLayout(fw, r, rt.layouts, tmpl.File, view)
+		} else {
+			renderErr = app.RenderRequest(fw, r, tmpl.File, tmpl.Block, view)
+		}
+	}
+	if renderErr != nil {
+		log.Printf("Render error for %s[%s]: %v", tmpl.File, tmpl.Block, renderErr)
+		app.renderFailure(w, r, renderErr)
+	}
+	afterRender(view, r, renderErr)
+}
+
+// withMiddleware wraps handler with middleware, the first one being outermost.
+func withMiddleware(handler http.Handler, middleware []func(http.Handler) http.Handler) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
 // Register registers a View at the given pattern.
 // If mux is nil, a new ServeMux is created.
 // Returns the mux for chaining.
@@ -88,63 +384,36 @@ func Register[V View[AC], AC any](
 	}
 
 	// Apply options
-	o := &options{}
-	for _, opt := range opts {
-		opt(o)
-	}
-
-	// Determine template file name
-	templateFileName := o.templateFileName
-	if templateFileName == "" {
-		templateFileName = typeNameOf[V]()
-	}
-
-	// Determine template block name
-	templateBlockName := o.templateBlockName
-	if templateBlockName == "" && !strings.Contains(o.templateFileName, ":") {
-		// No explicit block specified, auto-derive from base filename
-		templateBlockName = baseFileName(templateFileName)
+	o := app.newOptions(opts)
+
+	rt := o.newViewRoute(o.viewTemplate(typeNameOf[V]()), reflect.TypeFor[V](), reflect.TypeFor[Loader[AC]](), app.EnableJSON)
+	info := app.newRouteInfo(app.groupPrefix(), pattern, o.middleware)
+	info.Name, info.ViewType, info.Templates = o.name, typeNameOf[V](), rt.templateRefs()
+	info.targets = rt.renderTargets()
+	if info.Methods == nil {
+		info.Methods = viewMethods[AC](newInstance[V]())
 	}
+	app.addRoute(info)
 
 	// Create handler
-	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Create new instance of view
-		view := newInstance[V]()
-
-		// Load view data - pass the whole app
-		err, finished := view.Load(r, w, app)
-		if finished {
-			return
-		}
-
-		if err != nil {
-			log.Printf("View load error for %s[%s]: %v", templateFileName, templateBlockName, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Render template
-		if renderErr := app.RenderTemplate(w, templateFileName, templateBlockName, view); renderErr != nil {
-			log.Printf("Render error for %s[%s]: %v", templateFileName, templateBlockName, renderErr)
-			http.Error(w, "Template render error", http.StatusInternalServerError)
-		}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveView[AC](app, rt, newInstance[V](), w, r)
 	})
 
-	// Apply middleware in reverse order
-	for i := len(o.middleware) - 1; i >= 0; i-- {
-		handler = o.middleware[i](handler)
-	}
-
-	mux.Handle(pattern, handler)
+	mux.Handle(pattern, withMiddleware(handler, o.middleware))
 	return mux
 }
 
 // RegisterGroup registers a PageGroup under the given prefix.
 // The group's routes are mounted with the prefix stripped.
 //
+// Options act as defaults for every view the group registers (e.g.,
+// WithLayout for a section-wide layout, WithJSON), while WithMiddleware
+// wraps the whole group.
+//
 // Usage:
 //
-//	goal.RegisterGroup[GamesGroup](app, rootMux, "/games")
+//	goal.RegisterGroup[GamesGroup](app, rootMux, "/games", goal.WithLayout("BasePage"))
 func RegisterGroup[G PageGroup[AC], AC any](
 	app *App[AC],
 	mux *http.ServeMux,
@@ -155,9 +424,16 @@ func RegisterGroup[G PageGroup[AC], AC any](
 		mux = http.NewServeMux()
 	}
 
-	// Create group instance and get its routes
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Create group instance and get its routes, with opts as view defaults
 	group := newInstance[G]()
+	app.groups = append(app.groups, groupFrame{prefix: prefix, opts: opts})
 	groupMux := group.RegisterRoutes(app)
+	app.groups = app.groups[:len(app.groups)-1]
 
 	// Mount with StripPrefix
 	// Ensure prefix ends with / for proper matching
@@ -166,7 +442,7 @@ func RegisterGroup[G PageGroup[AC], AC any](
 		mountPattern = prefix + "/"
 	}
 
-	mux.Handle(mountPattern, http.StripPrefix(prefix, groupMux))
+	mux.Handle(mountPattern, withMiddleware(http.StripPrefix(prefix, groupMux), o.middleware))
 
 	return mux
 }
@@ -218,47 +494,28 @@ func SmartRegister[V interface {
 	}
 
 	// Apply options
-	o := &options{}
-	for _, opt := range opts {
-		opt(o)
-	}
+	o := app.newOptions(opts)
 
 	// Parse template specs
 	fullFileName, fullBlockName := ParseTemplateSpec(fullTemplateSpec)
 	fragFileName, fragBlockName := ParseTemplateSpec(fragmentTemplateSpec)
 
-	// Create handler
-	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		view := newInstance[V]()
-
-		err, finished := view.Load(r, w, app)
-		if finished {
-			return
-		}
-
-		if err != nil {
-			log.Printf("View load error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Choose template based on HTMX
-		fileName, blockName := fullFileName, fullBlockName
-		if view.ShouldRenderFragment() {
-			fileName, blockName = fragFileName, fragBlockName
-		}
+	rt := o.newViewRoute(TemplateRef{File: fullFileName, Block: fullBlockName}, reflect.TypeFor[V](), reflect.TypeFor[Loader[AC]](), app.EnableJSON)
+	rt.fragment = &TemplateRef{File: fragFileName, Block: fragBlockName}
+	info := app.newRouteInfo(app.groupPrefix(), pattern, o.middleware)
+	info.Name, info.ViewType, info.Templates = o.name, typeNameOf[V](), rt.templateRefs()
+	info.targets = rt.renderTargets()
+	if info.Methods == nil {
+		info.Methods = viewMethods[AC](newInstance[V]())
+	}
+	app.addRoute(info)
 
-		if renderErr := app.RenderTemplate(w, fileName, blockName, view); renderErr != nil {
-			http.Error(w, "Template render error", http.StatusInternalServerError)
-		}
+	// Create handler; HTMX detection picks the fragment template
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveView[AC](app, rt, newInstance[V](), w, r)
 	})
 
-	// Apply middleware
-	for i := len(o.middleware) - 1; i >= 0; i-- {
-		handler = o.middleware[i](handler)
-	}
-
-	mux.Handle(pattern, handler)
+	mux.Handle(pattern, withMiddleware(handler, o.middleware))
 	return mux
 }
 
@@ -0,0 +1,165 @@
+package goapplib
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tmplr "github.com/panyam/templar"
+)
+
+// TemplateReloader watches template directories and rebuilds the TemplateGroup
+// whenever an .html file is added, removed or modified.
+// Intended for local development only; it polls the file system.
+//
+// Rebuilding creates a fresh TemplateGroup (dropping all cached parsed
+// templates) and swaps it in atomically, so requests that are already
+// rendering finish with the previous group.
+type TemplateReloader struct {
+	// Paths are the template directories to watch.
+	Paths []string
+
+	// Interval is how often the directories are polled. Defaults to 500ms.
+	Interval time.Duration
+
+	// Base, if set, is the group being reloaded: new groups use its Loader
+	// and a copy of its Funcs, so custom funcs and loaders (e.g., FSLoader
+	// layers) behave as in production. EnableLocalDev sets it to the app's
+	// Templates.
+	Base *tmplr.TemplateGroup
+
+	// Setup builds a new TemplateGroup, taking precedence over Base.
+	// Without either, SetupTemplates(Paths...) is used.
+	Setup func() *tmplr.TemplateGroup
+
+	current   atomic.Pointer[tmplr.TemplateGroup]
+	mu        sync.Mutex
+	lastState string
+}
+
+// NewTemplateReloader creates a reloader for the given template directories.
+// The TemplateGroup is built on first use.
+func NewTemplateReloader(paths ...string) *TemplateReloader {
+	t := &TemplateReloader{Paths: paths}
+	t.lastState = t.scan()
+	return t
+}
+
+// Templates returns the current TemplateGroup, building it if needed.
+func (t *TemplateReloader) Templates() *tmplr.TemplateGroup {
+	if group := t.current.Load(); group != nil {
+		return group
+	}
+	group := t.build()
+	if t.current.CompareAndSwap(nil, group) {
+		return group
+	}
+	return t.current.Load()
+}
+
+// Reload rebuilds the TemplateGroup unconditionally.
+func (t *TemplateReloader) Reload() {
+	t.current.Store(t.build())
+}
+
+// Start polls the watched directories until ctx is done.
+func (t *TemplateReloader) Start(ctx context.Context) {
+	interval := t.Interval
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.checkForChanges()
+			}
+		}
+	}()
+}
+
+// checkForChanges rebuilds the group if the watched files changed since the last check.
+func (t *TemplateReloader) checkForChanges() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.scan()
+	if state == t.lastState {
+		return
+	}
+	t.lastState = state
+	log.Println("Templates changed, reloading")
+	t.Reload()
+}
+
+func (t *TemplateReloader) build() *tmplr.TemplateGroup {
+	switch {
+	case t.Setup != nil:
+		return t.Setup()
+	case t.Base != nil:
+		// A fresh group drops the parsed templates; the loaders read from disk
+		group := tmplr.NewTemplateGroup()
+		group.Loader = t.Base.Loader
+		group.AddFuncs(t.Base.Funcs)
+		return group
+	}
+	return SetupTemplates(t.Paths...)
+}
+
+// scan returns a fingerprint of all .html files (path, size, mtime) under Paths.
+func (t *TemplateReloader) scan() string {
+	var sb strings.Builder
+	for _, root := range t.Paths {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".html" {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(&sb, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return sb.String()
+}
+
+// templateLoaderNames describes the loaders of group for logs, e.g.
+// "*goapplib.FSLoader". The loaders of a LoaderList are listed one by one.
+func templateLoaderNames(group *tmplr.TemplateGroup) []string {
+	if group == nil || group.Loader == nil {
+		return []string{"no loader"}
+	}
+	list, ok := group.Loader.(*tmplr.LoaderList)
+	if !ok {
+		return []string{fmt.Sprintf("%T", group.Loader)}
+	}
+	// LoaderList does not export its loaders; only their types are read
+	var names []string
+	if loaders := reflect.ValueOf(list).Elem().FieldByName("loaders"); loaders.Kind() == reflect.Slice {
+		for i := 0; i < loaders.Len(); i++ {
+			if loader := loaders.Index(i); !loader.IsNil() {
+				names = append(names, loader.Elem().Type().String())
+			}
+		}
+	}
+	if list.DefaultLoader != nil {
+		names = append(names, fmt.Sprintf("%T", list.DefaultLoader))
+	}
+	if len(names) == 0 {
+		names = append(names, fmt.Sprintf("%T", list))
+	}
+	return names
+}
@@ -0,0 +1,162 @@
+package goapplib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// maxPooledBufferSize caps the size of buffers returned to the pool so that a
+// single very large page does not pin its memory forever.
+const maxPooledBufferSize = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns a buffer to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// StatusCoder is implemented by views that want to choose the HTTP status code
+// of their rendered response (e.g., 201 after a create, 404 for an empty result).
+// The status is written only after the template has rendered successfully.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// statusCodeOf returns the status code a view asked for, or 200.
+func statusCodeOf(view any) int {
+	if sc, ok := view.(StatusCoder); ok {
+		if code := sc.StatusCode(); code > 0 {
+			return code
+		}
+	}
+	return http.StatusOK
+}
+
+// writeBuffered writes a fully rendered body with the given status code.
+// Content-Type defaults to HTML and Content-Length is set from the buffer.
+func writeBuffered(w http.ResponseWriter, status int, buf *bytes.Buffer) error {
+	h := w.Header()
+	if h.Get("Content-Type") == "" {
+		h.Set("Content-Type", "text/html; charset=utf-8")
+	}
+	h.Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ErrorPageData is the view passed to App.ErrorPage when rendering an error.
+type ErrorPageData struct {
+	BasePage
+	Status     int    // HTTP status code being returned
+	StatusText string // Standard text for StatusCode (e.g., "Not Found")
+	Message    string // Message that is safe to show to users
+}
+
+// StatusCode implements StatusCoder so the error page is sent with its status.
+func (d *ErrorPageData) StatusCode() int {
+	return d.Status
+}
+
+// RenderError writes an error response with the given status code.
+// If App.ErrorPage is set it is rendered with an *ErrorPageData; otherwise (or
+// if the error page itself fails to render) a plain text error is written.
+// HTMX requests get App.ErrorFragment with status 200 so htmx swaps it, or a
+// toast trigger if that is not set.
+// Requests that want JSON get {"status": ..., "error": ...}.
+// Nothing must have been written to w before calling this.
+func (app *App[AppContext]) RenderError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	data := &ErrorPageData{
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Message:    message,
+	}
+	data.Title = data.StatusText
+
+	if WantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]any{"status": status, "error": message})
+		return
+	}
+
+	if IsHtmxRequest(r) && !IsBoostedRequest(r) {
+		// htmx only swaps 2xx responses, so the fragment is sent with 200
+		if app.ErrorFragment != "" {
+			fileName, blockName := ParseTemplateSpec(app.ErrorFragment)
+			rec := newResponseRecorder()
+			if err := app.RenderRequest(rec, r, fileName, blockName, data); err == nil {
+				rec.copyHeaders(w)
+				if app.ErrorTarget != "" {
+					NewHtmxResponse(w).Retarget(app.ErrorTarget).Reswap("innerHTML")
+				}
+				w.WriteHeader(http.StatusOK)
+				rec.body.WriteTo(w)
+				return
+			}
+		}
+		NewHtmxResponse(w).
+			AddTrigger("showToast", map[string]any{"message": message, "type": "error"}).
+			Reswap("none")
+		w.WriteHeader(status)
+		return
+	}
+
+	if spec := app.ErrorPage; spec != "" {
+		fileName, blockName := ParseTemplateSpec(spec)
+		if err := app.RenderRequest(w, r, fileName, blockName, data); err == nil {
+			return
+		}
+	}
+	http.Error(w, message, status)
+}
+
+// renderFailure reports a template rendering failure to the client.
+// In DevMode the full error, which names the template file and line, is shown
+// on a minimal built-in page so a bad edit is easy to locate.
+func (app *App[AppContext]) renderFailure(w http.ResponseWriter, r *http.Request, err error) {
+	failRequest(r, err)
+	untakeFlashes(r)
+	if !app.DevMode {
+		app.RenderError(w, r, http.StatusInternalServerError, "")
+		return
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	fmt.Fprintf(buf, devErrorPage, template.HTMLEscapeString(r.URL.Path), template.HTMLEscapeString(err.Error()))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writeBuffered(w, http.StatusInternalServerError, buf)
+}
+
+const devErrorPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>Template Error</title></head>
+<body style="font-family: sans-serif; margin: 2rem;">
+<h1 style="color: #b91c1c;">Template Error</h1>
+<p>While rendering <code>%s</code>:</p>
+<pre style="background: #fef2f2; border: 1px solid #fecaca; padding: 1rem; white-space: pre-wrap;">%s</pre>
+<p style="color: #6b7280;">Shown because DevMode is enabled. Fix the template and reload.</p>
+</body>
+</html>
+`
@@ -0,0 +1,150 @@
+package goapplib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// RequestCloser is implemented by request contexts (see
+// App.NewRequestContext) that hold resources, such as a DB transaction.
+// Close is called once the response is ready but before it is sent, so a
+// failing Close (e.g. a commit) is served as an error page. err is the first
+// error of the request (from Load, an action, a validation failure or the
+// render), so a transaction can commit when it is nil and roll back
+// otherwise. Contexts implementing only io.Closer are closed too.
+type RequestCloser interface {
+	Close(err error) error
+}
+
+// requestState is the request context of a request served by Register.
+type requestState struct {
+	value any
+
+	mu       sync.Mutex
+	err      error
+	pending  int  // Loads still running after a timeout
+	finished bool // The handler is done
+}
+
+type requestStateKey struct{}
+
+// RequestContext returns the request context created by
+// App.NewRequestContext for r, or the zero T if there is none:
+//
+//	func (p *GamePage) Load(r *http.Request, w http.ResponseWriter, app *goapplib.App[*ViewContext]) (error, bool) {
+//	    rc := goapplib.RequestContext[*RequestCtx](r)
+//	    game, err := rc.Tx.GetGame(p.GameId)
+//	    ...
+//	}
+func RequestContext[T any](r *http.Request) (value T) {
+	if state, _ := r.Context().Value(requestStateKey{}).(*requestState); state != nil {
+		value, _ = state.value.(T)
+	}
+	return value
+}
+
+// beginRequest creates the request context of r, if App.NewRequestContext
+// is set. done must be deferred by the handler. If buffer is set, the
+// handler writes to the returned ResponseWriter, which holds the response
+// until the request context is closed: a failing Close (say, a commit)
+// replaces it with an error page.
+func (app *App[AppContext]) beginRequest(w http.ResponseWriter, r *http.Request, buffer bool) (_ http.ResponseWriter, _ *http.Request, done func(), err error) {
+	if app.NewRequestContext == nil {
+		return w, r, func() {}, nil
+	}
+	value, err := app.NewRequestContext(r)
+	if err != nil {
+		return w, r, nil, err
+	}
+	state := &requestState{value: value}
+	r = r.WithContext(context.WithValue(r.Context(), requestStateKey{}, state))
+	out := w
+	var rec *responseRecorder
+	if buffer {
+		rec = newResponseRecorder()
+		w = rec
+	}
+	return w, r, func() {
+		// Called by defer, so a panicking handler rolls back too
+		p := recover()
+		if p != nil {
+			failRequest(r, fmt.Errorf("panic: %v", p))
+		}
+		state.mu.Lock()
+		state.finished = true
+		closeNow := state.pending == 0
+		state.mu.Unlock()
+		var closeErr error
+		if closeNow {
+			closeErr = state.close()
+		}
+		if p != nil {
+			if closeErr != nil {
+				log.Printf("Error closing request context for %s %s: %v", r.Method, r.URL.Path, closeErr)
+			}
+			panic(p)
+		}
+		switch {
+		case rec == nil:
+			if closeErr != nil {
+				log.Printf("Error closing request context for %s %s: %v", r.Method, r.URL.Path, closeErr)
+			}
+		case closeErr != nil:
+			app.HandleError(out, r, fmt.Errorf("closing request context: %w", closeErr))
+		default:
+			rec.replay(out)
+		}
+	}, nil
+}
+
+// failRequest records err as the outcome of r for RequestCloser.Close.
+// Only the first error is kept.
+func failRequest(r *http.Request, err error) {
+	state, _ := r.Context().Value(requestStateKey{}).(*requestState)
+	if state == nil || err == nil {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.err == nil {
+		state.err = err
+	}
+}
+
+// holdRequest keeps the request context of r open until the returned func is
+// called, for a Load that may outlive its handler (see WithTimeout).
+func holdRequest(r *http.Request) (release func()) {
+	state, _ := r.Context().Value(requestStateKey{}).(*requestState)
+	if state == nil {
+		return func() {}
+	}
+	state.mu.Lock()
+	state.pending++
+	state.mu.Unlock()
+	return func() {
+		state.mu.Lock()
+		state.pending--
+		closeNow := state.finished && state.pending == 0
+		state.mu.Unlock()
+		if closeNow {
+			if err := state.close(); err != nil {
+				log.Printf("Error closing request context for %s %s: %v", r.Method, r.URL.Path, err)
+			}
+		}
+	}
+}
+
+// close closes the request context with the outcome of the request.
+func (s *requestState) close() error {
+	switch c := s.value.(type) {
+	case RequestCloser:
+		return c.Close(s.err)
+	case io.Closer:
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package goapplib
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// txContext is a request context recording how it was closed.
+type txContext struct {
+	closed   chan error
+	closeErr error // Returned by Close, e.g. a failed commit
+}
+
+func (tx *txContext) Close(err error) error {
+	tx.closed <- err
+	return tx.closeErr
+}
+
+// txPage is a view whose Load is set by the test.
+type txPage struct {
+	load func(r *http.Request) error
+}
+
+func (p *txPage) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	if p.load != nil {
+		return p.load(r), false
+	}
+	return nil, false
+}
+
+// serveTx serves a request to a txPage with a txContext and returns the
+// response and the context.
+func serveTx(t *testing.T, load func(*http.Request) error, renderErr error, closeErr error, opts ...Option) (*httptest.ResponseRecorder, *txContext) {
+	t.Helper()
+	tx := &txContext{closed: make(chan error, 1), closeErr: closeErr}
+	app := NewApp(struct{}{}, nil)
+	app.NewRequestContext = func(r *http.Request) (any, error) { return tx, nil }
+	app.RenderTemplateFunc = func(w http.ResponseWriter, file, block string, view any) error {
+		if renderErr != nil {
+			return renderErr
+		}
+		_, err := w.Write([]byte("rendered"))
+		return err
+	}
+	mux := app.NewMux().Page("/", func() View[struct{}] { return &txPage{load: load} }, opts...).Build()
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w, tx
+}
+
+// closedWith returns the error tx was closed with, failing if it was not.
+func closedWith(t *testing.T, tx *txContext) error {
+	t.Helper()
+	select {
+	case err := <-tx.closed:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("request context was not closed")
+		return nil
+	}
+}
+
+func TestRequestContextCommitsOnSuccess(t *testing.T) {
+	w, tx := serveTx(t, nil, nil, nil)
+	if err := closedWith(t, tx); err != nil {
+		t.Fatalf("closed with %v, want nil", err)
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "rendered" {
+		t.Fatalf("got %d %q, want the rendered page", w.Code, w.Body.String())
+	}
+}
+
+func TestRequestContextRollsBackOnFailure(t *testing.T) {
+	loadErr := errors.New("backend down")
+	renderErr := errors.New("bad template")
+	tests := []struct {
+		name      string
+		load      func(*http.Request) error
+		renderErr error
+		wantErr   error
+	}{
+		{"load", func(*http.Request) error { return loadErr }, nil, loadErr},
+		{"render", nil, renderErr, renderErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, tx := serveTx(t, tt.load, tt.renderErr, nil)
+			if err := closedWith(t, tx); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("closed with %v, want %v", err, tt.wantErr)
+			}
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("got %d, want 500", w.Code)
+			}
+		})
+	}
+}
+
+func TestRequestContextCloseErrorIsServed(t *testing.T) {
+	w, tx := serveTx(t, nil, nil, errors.New("commit failed"))
+	if err := closedWith(t, tx); err != nil {
+		t.Fatalf("closed with %v, want nil", err)
+	}
+	if w.Code != http.StatusInternalServerError || w.Body.String() == "rendered" {
+		t.Fatalf("got %d %q, want an error page instead of the page", w.Code, w.Body.String())
+	}
+}
+
+func TestRequestContextWaitsForTimedOutLoad(t *testing.T) {
+	unblock := make(chan struct{})
+	returned := make(chan struct{})
+	load := func(r *http.Request) error {
+		defer close(returned)
+		<-unblock // A backend call that ignores the deadline
+		return nil
+	}
+
+	w, tx := serveTx(t, load, nil, nil, WithTimeout(10*time.Millisecond))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("got %d, want 504", w.Code)
+	}
+	select {
+	case err := <-tx.closed:
+		t.Fatalf("closed with %v while Load was still running", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(unblock)
+	if err := closedWith(t, tx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("closed with %v, want the deadline error", err)
+	}
+	<-returned
+}
+
+// txActionPage is a txPage whose POST action fails.
+type txActionPage struct {
+	txPage
+	err error
+}
+
+func (p *txActionPage) Post(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	return p.err, false
+}
+
+func TestRequestContextRollsBackOnActionError(t *testing.T) {
+	actionErr := NotFound("no such game")
+	tx := &txContext{closed: make(chan error, 1)}
+	app := NewApp(struct{}{}, nil)
+	app.NewRequestContext = func(r *http.Request) (any, error) { return tx, nil }
+	mux := app.NewMux().Page("/", func() View[struct{}] { return &txActionPage{err: actionErr} }).Build()
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	if err := closedWith(t, tx); !errors.Is(err, actionErr) {
+		t.Fatalf("closed with %v, want %v", err, actionErr)
+	}
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,159 @@
+package goapplib
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// RouteLister lists registered routes. App implements it.
+type RouteLister interface {
+	Routes() []RouteInfo
+}
+
+// Routes returns the routes registered through Register, SmartRegister and
+// MuxBuilder, in registration order, including those of the apps mounted
+// with Mount. Handlers added to a ServeMux directly (including with
+// RegisterFunc and RegisterHandler) are not known to the app.
+func (app *App[AppContext]) Routes() []RouteInfo {
+	app.routesMu.Lock()
+	own := append([]RouteInfo(nil), app.routes...)
+	mounts := append([]mountedApp(nil), app.mounts...)
+	app.routesMu.Unlock()
+
+	var routes []RouteInfo
+	next := 0
+	for _, m := range mounts {
+		routes, next = append(routes, own[next:m.at]...), m.at
+		for _, route := range m.app.Routes() {
+			if m.name != "" && route.Name != "" {
+				route.Name = m.name + "." + route.Name
+			}
+			routes = append(routes, route)
+		}
+	}
+	return append(routes, own[next:]...)
+}
+
+// newRouteInfo describes a route registered at pattern on a mux mounted at
+// prefix, wrapped in middleware and in the middleware of the groups being
+// registered and of the app's mount.
+func (app *App[AppContext]) newRouteInfo(prefix, pattern string, middleware []func(http.Handler) http.Handler) RouteInfo {
+	info := RouteInfo{
+		Pattern: joinPattern(prefix, pattern),
+		Group:   strings.TrimSuffix(prefix, "/"),
+	}
+	if method, _ := splitPattern(pattern); method != "" {
+		info.Methods = []string{strings.TrimSpace(method)}
+	}
+	var frames [][]Option
+	if app.mount != nil {
+		frames = append(frames, app.mount.opts)
+	}
+	for _, group := range app.groups {
+		frames = append(frames, group.opts)
+	}
+	for _, opts := range frames {
+		o := &options{}
+		for _, opt := range opts {
+			opt(o)
+		}
+		for _, mw := range o.middleware {
+			info.Middleware = append(info.Middleware, funcName(mw))
+		}
+	}
+	for _, mw := range middleware {
+		info.Middleware = append(info.Middleware, funcName(mw))
+	}
+	return info
+}
+
+// viewMethods returns the methods a view route without a method in its
+// pattern accepts, or nil if Load serves every method.
+func viewMethods[AC any](view View[AC]) []string {
+	if _, hasActions := actionFor(view, ""); hasActions {
+		return allowedMethods(view)
+	}
+	return nil
+}
+
+// closureSuffixRe matches the suffix the runtime gives closures ("New.func1",
+// nested as "New.func2.1") and method values ("(*T).Handle-fm").
+var closureSuffixRe = regexp.MustCompile(`(\.func\d+(\.\d+)*)+$|-fm$`)
+
+// funcName returns the name of a func without its package path, e.g.
+// "auth.RequireRole" for the middleware returned by auth.RequireRole("admin").
+func funcName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "?"
+	}
+	return shortFuncName(f.Name())
+}
+
+// shortFuncName trims the package path and closure suffix from a func name
+// reported by the runtime.
+func shortFuncName(name string) string {
+	name = name[strings.LastIndex(name, "/")+1:]
+	return closureSuffixRe.ReplaceAllString(name, "")
+}
+
+// RoutesHandler serves the routes of lister as an HTML table, or as JSON if
+// the request asks for it (see WantsJSON). It is meant for development:
+// WebAppServer serves it when AllowLocalDev is on (see RoutesPath).
+func RoutesHandler(lister RouteLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routes := lister.Routes()
+		if WantsJSON(r) {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(routes); err != nil {
+				log.Printf("Error writing routes: %v", err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := routesPage.Execute(w, routes); err != nil {
+			log.Printf("Error rendering routes page: %v", err)
+		}
+	})
+}
+
+var routesPage = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Routes</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2937; }
+table { border-collapse: collapse; width: 100%; font-size: 0.875rem; }
+th, td { text-align: left; vertical-align: top; padding: 0.375rem 0.75rem; border-bottom: 1px solid #e5e7eb; }
+th { background: #f3f4f6; }
+code { font-family: ui-monospace, monospace; }
+.muted { color: #9ca3af; }
+</style>
+</head>
+<body>
+<h1>Routes</h1>
+<p>{{ len . }} routes &middot; <a href="?format=json">JSON</a></p>
+<table>
+<tr><th>Methods</th><th>Pattern</th><th>Name</th><th>View</th><th>Templates</th><th>Middleware</th><th>Group</th></tr>
+{{- range . }}
+<tr>
+<td>{{ range .Methods }}{{ . }} {{ else }}<span class="muted">any</span>{{ end }}</td>
+<td><code>{{ .Pattern }}</code></td>
+<td>{{ .Name }}</td>
+<td>{{ .ViewType }}</td>
+<td>{{ range .Templates }}<code>{{ .File }}{{ if .Block }}:{{ .Block }}{{ end }}</code><br>{{ end }}</td>
+<td>{{ range .Middleware }}{{ . }}<br>{{ end }}</td>
+<td><code>{{ .Group }}</code></td>
+</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
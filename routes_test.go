@@ -0,0 +1,61 @@
+package goapplib
+
+import (
+	"net/http"
+	"testing"
+)
+
+func requireHeader(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type routeTester struct{}
+
+func (routeTester) Handle(next http.Handler) http.Handler { return next }
+
+// TestFuncName checks that closure and method value suffixes are trimmed
+// from middleware names.
+func TestFuncName(t *testing.T) {
+	tests := []struct {
+		fn   any
+		want string
+	}{
+		{CORS, "goapplib.CORS"},
+		{requireHeader("X-Admin"), "goapplib.requireHeader"},
+		{requireHeader("X-Admin")(nil), "goapplib.requireHeader"},
+		{routeTester{}.Handle, "goapplib.routeTester.Handle"},
+	}
+	for _, tt := range tests {
+		if got := funcName(tt.fn); got != tt.want {
+			t.Errorf("funcName = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// TestShortFuncName covers runtime names of every toolchain, including
+// nested closures numbered "func2.1".
+func TestShortFuncName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"github.com/acme/auth.RequireRole", "auth.RequireRole"},
+		{"github.com/acme/auth.RequireRole.func1", "auth.RequireRole"},
+		{"github.com/acme/auth.RequireRole.func1.func2", "auth.RequireRole"},
+		{"github.com/acme/auth.RequireRole.func2.1", "auth.RequireRole"},
+		{"github.com/acme/auth.RequireRole.func2.1.3", "auth.RequireRole"},
+		{"github.com/acme/auth.RequireRole.func1.2.func1", "auth.RequireRole"},
+		{"github.com/acme/auth.Chain[...].func1", "auth.Chain[...]"},
+		{"github.com/acme/auth.(*Guard).Check-fm", "auth.(*Guard).Check"},
+		{"main.logRequests", "main.logRequests"},
+	}
+	for _, tt := range tests {
+		if got := shortFuncName(tt.name); got != tt.want {
+			t.Errorf("shortFuncName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package goapplib
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+
+	tmplr "github.com/panyam/templar"
+)
+
+// Default blocks rendered by WithStreaming (defined in templates/BasePage.html).
+const (
+	DefaultStreamHeadBlock = "BasePageHead"
+	DefaultStreamBodyBlock = "BasePageBody"
+)
+
+// WithStreaming renders the page in two parts: the head block is rendered and
+// flushed before View.Load runs, so the browser starts fetching CSS and
+// scripts while the page data loads; the body block is sent once loading
+// finishes. Optional arguments name the head and body blocks, which default
+// to BasePageHead and BasePageBody.
+//
+// The head is rendered with the new, unloaded view; implement HeadLoader to
+// fill in what it needs (e.g., the title). Because the status line is sent
+// with the head, a streamed page cannot change its status code; a *Redirect
+// returned by Load becomes a meta refresh, and errors after the head is sent
+// are rendered inline in the body instead. A response Load writes itself
+// (e.g., with http.Redirect) is discarded and the page is closed empty.
+// JSON and HTMX requests are never streamed, and the render hooks
+// (BeforeRenderer, TemplateOverrider, AfterRenderer) do not apply.
+func WithStreaming(blocks ...string) Option {
+	return func(o *options) {
+		o.stream = true
+		o.streamHead, o.streamBody = DefaultStreamHeadBlock, DefaultStreamBodyBlock
+		if len(blocks) > 0 {
+			o.streamHead = blocks[0]
+		}
+		if len(blocks) > 1 {
+			o.streamBody = blocks[1]
+		}
+	}
+}
+
+// HeadLoader is implemented by streamed views that need data in the head
+// block (title, meta tags). LoadHead runs before the head is flushed and
+// before Load.
+type HeadLoader interface {
+	LoadHead(r *http.Request)
+}
+
+// canStream reports whether a request to a streaming route can be streamed.
+func canStream(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	return !WantsJSON(r) && !(IsHtmxRequest(r) && !IsBoostedRequest(r))
+}
+
+// serveStreaming flushes the head block, loads the view and then writes the body block.
+func serveStreaming[AC any](app *App[AC], rt *viewRoute, view View[AC], w http.ResponseWriter, r *http.Request) {
+	var root *tmplr.Template
+	var label string
+	if len(rt.layouts) > 0 {
+		root, _ = layoutRoot(rt.layouts, rt.full.File)
+		label = root.Name
+	} else {
+		var err error
+		if root, err = app.loadRoot(rt.full.File); err != nil {
+			app.renderFailure(w, r, err)
+			return
+		}
+		label = rt.full.File
+	}
+
+	if hl, ok := view.(HeadLoader); ok {
+		hl.LoadHead(r)
+	}
+
+	// Flashes are taken now, while the cookie can still be cleared
+	fw := app.takeFlashes(w, r)
+
+	// Head: rendered in full before anything is written
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := app.renderRoot(buf, r, root, label, rt.streamHead, view); err != nil {
+		app.renderFailure(w, r, err)
+		return
+	}
+	w = fw
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+	if err := http.NewResponseController(w).Flush(); err != nil {
+		log.Printf("Streaming flush not supported for %s: %v", r.URL.Path, err)
+	}
+
+	// Body: load, then render fully before writing. Load writes to a
+	// recorder since the status line and headers are already sent.
+	rec := newResponseRecorder()
+	err, finished, timedOut := loadView(app, rt, view, rec, r)
+	if timedOut {
+		failRequest(r, context.DeadlineExceeded)
+		app.writeInlineError(w, r, http.StatusGatewayTimeout, "This page took too long to load. Please try again.")
+		return
+	}
+	if finished {
+		if location := rec.header.Get("Location"); location != "" {
+			log.Printf("Ignoring redirect to %s written by Load of streamed page %s; return a *Redirect instead", location, r.URL.Path)
+		} else {
+			log.Printf("Ignoring response written by Load of streamed page %s", r.URL.Path)
+		}
+		fmt.Fprint(w, "<body></body>\n</html>\n")
+		return
+	}
+	rec.body.WriteTo(w)
+	if rd, ok := asRedirect(err); ok {
+		// The status line is gone, so redirect from the page
+		fmt.Fprintf(w, "<body>\n<meta http-equiv=\"refresh\" content=\"0;url=%s\">\n</body>\n</html>\n", template.HTMLEscapeString(rd.URL))
+		return
+	}
+	if err != nil {
+		failRequest(r, err)
+		status, message := ErrorStatus(err)
+		log.Printf("Error handling %s %s [%d]: %v", r.Method, r.URL.Path, status, err)
+		app.writeInlineError(w, r, status, message)
+		return
+	}
+
+	buf.Reset()
+	if err := app.renderRoot(buf, r, root, label, rt.streamBody, view); err != nil {
+		failRequest(r, err)
+		message := http.StatusText(http.StatusInternalServerError)
+		if app.DevMode {
+			message = err.Error()
+		}
+		app.writeInlineError(w, r, http.StatusInternalServerError, message)
+		return
+	}
+	buf.WriteTo(w)
+}
+
+// writeInlineError completes a streamed page whose head was already sent with
+// an error region in place of the body. It uses App.ErrorFragment if set.
+func (app *App[AppContext]) writeInlineError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if app.ErrorFragment != "" {
+		fileName, blockName := ParseTemplateSpec(app.ErrorFragment)
+		data := &ErrorPageData{Status: status, StatusText: http.StatusText(status), Message: message}
+		if app.renderTo(buf, r, fileName, blockName, data) != nil {
+			buf.Reset()
+		}
+	}
+	if buf.Len() == 0 {
+		fmt.Fprintf(buf, `<div role="alert" class="m-8 p-4 border border-red-200 rounded-lg bg-red-50 text-red-700">%s</div>`,
+			template.HTMLEscapeString(message))
+	}
+	fmt.Fprintf(w, "<body>\n%s\n</body>\n</html>\n", buf.String())
+}
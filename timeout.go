@@ -0,0 +1,102 @@
+package goapplib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// WithTimeout bounds View.Load by d. Load gets a request whose context has
+// the deadline; if Load has not returned when it passes, the client gets a
+// 504 timeout page (App.TimeoutPage, or the error page/fragment) right away
+// instead of waiting on a hung backend call.
+//
+// While the deadline applies, Load writes to a buffer that is copied to the
+// real response when Load returns in time, so redirects keep working.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// loadView binds the tagged fields of view (see Bind), loads the route's
+// embedded mixins and then calls view.Load, bounded by the route's timeout if
+// it is positive.
+// timedOut is true if the deadline passed first; nothing has been written then.
+func loadView[AC any](app *App[AC], rt *viewRoute, view View[AC], w http.ResponseWriter, r *http.Request) (err error, finished bool, timedOut bool) {
+	load := func(r *http.Request, w http.ResponseWriter) (error, bool) {
+		if err := bindView(r, view); err != nil {
+			return err, false
+		}
+		if err, finished := loadMixins(view, rt, r, w, app); finished || err != nil {
+			return err, finished
+		}
+		return view.Load(r, w, app)
+	}
+
+	timeout := rt.timeout
+	if timeout <= 0 {
+		err, finished = load(r, w)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	type result struct {
+		err      error
+		finished bool
+	}
+	rec := newResponseRecorder()
+	done := make(chan result, 1)
+	release := holdRequest(r)
+	go func() {
+		defer release()
+		// This goroutine may outlive the handler, so a panic must not crash the server
+		defer func() {
+			if p := recover(); p != nil {
+				log.Printf("View load panic for %s: %v", r.URL.Path, p)
+				done <- result{err: fmt.Errorf("view load panic: %v", p)}
+			}
+		}()
+		err, finished := load(r.WithContext(ctx), rec)
+		done <- result{err, finished}
+	}()
+
+	select {
+	case res := <-done:
+		if res.finished {
+			rec.replay(w)
+		} else {
+			rec.copyHeaders(w)
+		}
+		return res.err, res.finished, false
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.Printf("View load timed out after %s for %s %s", timeout, r.Method, r.URL.Path)
+			return nil, false, true
+		}
+		return ctx.Err(), false, false
+	}
+}
+
+// RenderTimeout writes a 504 response for a request whose deadline passed.
+// It renders App.TimeoutPage for full page requests if set, and otherwise
+// falls back to RenderError (which handles HTMX and JSON requests).
+func (app *App[AppContext]) RenderTimeout(w http.ResponseWriter, r *http.Request) {
+	const status = http.StatusGatewayTimeout
+	failRequest(r, context.DeadlineExceeded)
+	message := "This page took too long to load. Please try again."
+	if app.TimeoutPage != "" && !WantsJSON(r) && !(IsHtmxRequest(r) && !IsBoostedRequest(r)) {
+		fileName, blockName := ParseTemplateSpec(app.TimeoutPage)
+		data := &ErrorPageData{Status: status, StatusText: http.StatusText(status), Message: message}
+		data.Title = data.StatusText
+		if err := app.RenderRequest(w, r, fileName, blockName, data); err == nil {
+			return
+		}
+	}
+	app.RenderError(w, r, status, message)
+}
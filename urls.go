@@ -0,0 +1,174 @@
+package goapplib
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// wildcardRe matches the {name}, {name...} and {$} wildcards of a pattern.
+var wildcardRe = regexp.MustCompile(`\{([^}]*)\}`)
+
+// joinPattern prefixes the path of a ServeMux pattern ("[METHOD ][HOST]/path")
+// with the mount prefix of a group.
+func joinPattern(prefix, pattern string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return pattern
+	}
+	method, path := splitPattern(pattern)
+	if !strings.HasPrefix(path, "/") {
+		return pattern // Host patterns are not mounted under a prefix
+	}
+	return method + prefix + path
+}
+
+// splitPattern splits a ServeMux pattern into its "METHOD " part and the rest.
+func splitPattern(pattern string) (method, rest string) {
+	if idx := strings.IndexAny(pattern, " \t"); idx >= 0 {
+		return pattern[:idx+1], strings.TrimLeft(pattern[idx+1:], " \t")
+	}
+	return "", pattern
+}
+
+// patternPath returns the path of a ServeMux pattern, without method and host.
+func patternPath(pattern string) string {
+	_, path := splitPattern(pattern)
+	if idx := strings.Index(path, "/"); idx > 0 {
+		path = path[idx:]
+	}
+	return path
+}
+
+// URLFor builds the path of the route named name (see WithName), filling its
+// wildcards from params, which are name/value pairs:
+//
+//	app.URLFor("games.edit", "id", game.Id) // "/games/42/edit"
+//
+// Values are formatted with fmt.Sprint and escaped. Params that are not
+// wildcards of the pattern are added as query parameters. Templates use the
+// urlFor func:
+//
+//	<a href="{{ urlFor "games.edit" "id" .Game.Id }}">Edit</a>
+//
+// Names are looked up in the app, then in the apps mounted in it (see Mount),
+// then from the outermost app it is mounted in.
+func (app *App[AppContext]) URLFor(name string, params ...any) (string, error) {
+	path, found, err := app.findURL(name, params)
+	if !found && app.mount != nil {
+		path, found, err = app.rootApp().findURL(name, params)
+	}
+	if !found {
+		return "", fmt.Errorf("urlFor: no route named %q", name)
+	}
+	return path, err
+}
+
+// findURL builds the URL of the route named name in app or the apps mounted
+// in it. found is false if there is no such route.
+func (app *App[AppContext]) findURL(name string, params []any) (path string, found bool, err error) {
+	app.routesMu.Lock()
+	idx, ok := app.routeNames[name]
+	var pattern string
+	if ok {
+		pattern = app.routes[idx].Pattern
+	}
+	mounts := append([]mountedApp(nil), app.mounts...)
+	app.routesMu.Unlock()
+
+	if ok {
+		path, err = buildURL(name, pattern, params)
+		return path, true, err
+	}
+	for _, m := range mounts {
+		rest, ok := name, true
+		if m.name != "" {
+			rest, ok = strings.CutPrefix(name, m.name+".")
+		}
+		if !ok {
+			continue
+		}
+		if path, found, err = m.app.findURL(rest, params); found {
+			return path, true, err
+		}
+	}
+	return "", false, nil
+}
+
+// buildURL fills the wildcards of pattern, registered as the route named
+// name, from params (see URLFor).
+func buildURL(name, pattern string, params []any) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("urlFor %q: params must be name/value pairs", name)
+	}
+	values := make(map[string]string, len(params)/2)
+	var order []string
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("urlFor %q: param name %v is not a string", name, params[i])
+		}
+		values[key] = fmt.Sprint(params[i+1])
+		order = append(order, key)
+	}
+
+	var missing []string
+	path := wildcardRe.ReplaceAllStringFunc(patternPath(pattern), func(wildcard string) string {
+		key := wildcard[1 : len(wildcard)-1]
+		if key == "$" {
+			return ""
+		}
+		rest := strings.HasSuffix(key, "...")
+		key = strings.TrimSuffix(key, "...")
+		value, ok := values[key]
+		if !ok {
+			missing = append(missing, key)
+			return ""
+		}
+		delete(values, key)
+		if rest {
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			return strings.Join(segments, "/")
+		}
+		return url.PathEscape(value)
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("urlFor %q: missing params %s", name, strings.Join(missing, ", "))
+	}
+
+	if len(values) > 0 {
+		query := url.Values{}
+		for _, key := range order {
+			if value, ok := values[key]; ok {
+				query.Set(key, value)
+			}
+		}
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+// URLFormat returns the path of the route named name with the wildcard param
+// replaced by %s, for helpers that take a format string, such as
+// EntityListingData's URL formats:
+//
+//	format, _ := app.URLFormat("games.view", "id") // "/games/%s"
+//	data := goapplib.NewEntityListingData[*Game]("Games", format)
+//
+// Other wildcards are filled from params as in URLFor.
+func (app *App[AppContext]) URLFormat(name string, param string, params ...any) (string, error) {
+	const marker = "\x00"
+	path, err := app.URLFor(name, append([]any{param, marker}, params...)...)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(path, url.PathEscape(marker))
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(part, "%", "%%")
+	}
+	return strings.Join(parts, "%s"), nil
+}
@@ -0,0 +1,92 @@
+package goapplib
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// urlPage is a view registered only to be named.
+type urlPage struct{}
+
+func (p *urlPage) Load(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+	return nil, false
+}
+
+// adminGroup registers its routes under the prefix given to RegisterGroup.
+type adminGroup struct{}
+
+func (g *adminGroup) RegisterRoutes(app *App[struct{}]) *http.ServeMux {
+	mux := Register[*urlPage](app, nil, "/users/{id}", WithName("user"))
+	return RegisterGroup[*reportsGroup](app, mux, "/reports", WithName("reports"))
+}
+
+type reportsGroup struct{}
+
+func (g *reportsGroup) RegisterRoutes(app *App[struct{}]) *http.ServeMux {
+	return Register[*urlPage](app, nil, "GET /{year}", WithName("year"))
+}
+
+func newURLApp() *App[struct{}] {
+	app := NewApp(struct{}{}, nil)
+	mux := Register[*urlPage](app, nil, "/{$}", WithName("home"))
+	Register[*urlPage](app, mux, "GET /games/{id}/edit", WithName("games.edit"))
+	Register[*urlPage](app, mux, "/files/{path...}", WithName("files"))
+	RegisterGroup[*adminGroup](app, mux, "/admin", WithName("admin"))
+	app.NewMux().Group("/api", func(b *MuxBuilder[struct{}]) {
+		b.Page("/games/{id}", func() View[struct{}] { return &urlPage{} }, WithName("api.game"))
+	})
+	return app
+}
+
+func TestURLFor(t *testing.T) {
+	app := newURLApp()
+	tests := []struct {
+		name    string
+		params  []any
+		want    string
+		wantErr string
+	}{
+		{"home", nil, "/", ""},
+		{"games.edit", []any{"id", 42}, "/games/42/edit", ""},
+		{"games.edit", []any{"id", "a b/c"}, "/games/a%20b%2Fc/edit", ""},
+		{"files", []any{"path", "docs/read me.txt"}, "/files/docs/read%20me.txt", ""},
+		{"games.edit", []any{"id", 1, "tab", "rules", "page", 2}, "/games/1/edit?page=2&tab=rules", ""},
+		{"games.edit", []any{"id", 1, "q", "a&b=c"}, "/games/1/edit?q=a%26b%3Dc", ""},
+		{"admin.user", []any{"id", 7}, "/admin/users/7", ""},
+		{"admin.reports.year", []any{"year", 2024}, "/admin/reports/2024", ""},
+		{"api.game", []any{"id", 3}, "/api/games/3", ""},
+		{"games.edit", nil, "", "missing params id"},
+		{"games.edit", []any{"id"}, "", "name/value pairs"},
+		{"games.edit", []any{1, 2}, "", "is not a string"},
+		{"user", []any{"id", 7}, "", `no route named "user"`},
+		{"nosuch", nil, "", `no route named "nosuch"`},
+	}
+	for _, tt := range tests {
+		got, err := app.URLFor(tt.name, tt.params...)
+		switch {
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("URLFor(%q, %v) = %q, %v; want error %q", tt.name, tt.params, got, err, tt.wantErr)
+		case tt.wantErr == "" && (err != nil || got != tt.want):
+			t.Errorf("URLFor(%q, %v) = %q, %v; want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+}
+
+func TestURLFormat(t *testing.T) {
+	app := newURLApp()
+	got, err := app.URLFormat("games.edit", "id", "tab", "100%")
+	if want := "/games/%s/edit?tab=100%%25"; err != nil || got != want {
+		t.Fatalf("got %q, %v; want %q", got, err, want)
+	}
+}
+
+func TestRouteNamesMustBeUnique(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a duplicate name did not panic")
+		}
+	}()
+	app := newURLApp()
+	Register[*urlPage](app, nil, "/other", WithName("home"))
+}
@@ -0,0 +1,138 @@
+package goapplib
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	tmplr "github.com/panyam/templar"
+)
+
+// TemplateRef identifies a template file and the block rendered from it.
+// An empty Block means the entire file is rendered.
+type TemplateRef struct {
+	File  string `json:"file"`
+	Block string `json:"block,omitempty"`
+}
+
+// RouteInfo describes a route registered with the app (see App.Routes).
+type RouteInfo struct {
+	Name       string        `json:"name,omitempty"`       // Set with WithName, including group name prefixes
+	Pattern    string        `json:"pattern"`              // Pattern including the prefixes of enclosing groups
+	Methods    []string      `json:"methods,omitempty"`    // Methods served; empty if any method is
+	ViewType   string        `json:"viewType,omitempty"`   // Name of the view type (e.g., "GameListingPage"); empty for handlers
+	Templates  []TemplateRef `json:"templates,omitempty"`  // Templates the view may render
+	Middleware []string      `json:"middleware,omitempty"` // Names of the middleware wrapping the route, outermost first
+	Group      string        `json:"group,omitempty"`      // Mount prefix of the enclosing group, if any
+
+	targets []renderTarget // What the view renders, checked by Validate
+}
+
+// renderTarget is a root template a view renders and the blocks executed
+// from it.
+type renderTarget struct {
+	layouts []string // Layouts the file is rendered in (see WithLayout)
+	file    string
+	blocks  []string // "" executes the whole file
+}
+
+// addRoute records a registered route. Route names must be unique.
+func (app *App[AppContext]) addRoute(info RouteInfo) {
+	app.routesMu.Lock()
+	defer app.routesMu.Unlock()
+	if info.Name != "" {
+		if _, exists := app.routeNames[info.Name]; exists {
+			panic(fmt.Sprintf("goapplib: route name %q is already registered", info.Name))
+		}
+		if app.routeNames == nil {
+			app.routeNames = map[string]int{}
+		}
+		app.routeNames[info.Name] = len(app.routes)
+	}
+	app.routes = append(app.routes, info)
+}
+
+// Validate checks the templates of every view registered through Register,
+// SmartRegister and MuxBuilder.Page. Each template is loaded and parsed
+// through the template loader exactly as a render would (following includes,
+// layouts and with the app's funcs), so syntax errors and undefined funcs
+// are reported, and each block the view executes must be defined. Returns
+// nil if all views are valid, otherwise an error listing every broken route.
+// Apps mounted with Mount are validated against their own templates. Call it
+// at startup or from a unit test to fail fast.
+//
+// App.RequestFuncs is called with a placeholder request to learn the names
+// of the request-scoped funcs.
+func (app *App[AppContext]) Validate() error {
+	app.routesMu.Lock()
+	routes := append([]RouteInfo(nil), app.routes...)
+	mounts := append([]mountedApp(nil), app.mounts...)
+	app.routesMu.Unlock()
+
+	var errs []error
+	if templates := app.templateGroup(); templates != nil && templates.Loader != nil {
+		funcs := app.validationFuncs()
+		for _, route := range routes {
+			for _, target := range route.targets {
+				if err := validateTarget(templates, target, funcs); err != nil {
+					errs = append(errs, fmt.Errorf("%s (%s): %w", route.Pattern, route.ViewType, err))
+				}
+			}
+		}
+	}
+	for _, m := range mounts {
+		if err := m.app.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validationFuncs returns the request-scoped funcs templates are parsed
+// with, bound to a placeholder request since Validate runs outside of one.
+func (app *App[AppContext]) validationFuncs() (funcs template.FuncMap) {
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	defer func() {
+		if p := recover(); p != nil {
+			// App.RequestFuncs needs a real request; its funcs are unknown
+			funcs = app.requestFuncs(nil, nil)
+		}
+	}()
+	return app.requestFuncs(r, nil)
+}
+
+// validateTarget loads and parses the root template of target as a render
+// would and checks that its blocks are defined.
+func validateTarget(templates *tmplr.TemplateGroup, target renderTarget, funcs template.FuncMap) (err error) {
+	if target.file == "" {
+		return errors.New("no template file")
+	}
+	var root *tmplr.Template
+	if len(target.layouts) > 0 {
+		root, _ = layoutRoot(target.layouts, target.file)
+	} else {
+		fileName := target.file + ".html"
+		tmpls, err := templates.Loader.Load(fileName, "")
+		if err != nil {
+			return fmt.Errorf("cannot load %s: %w", fileName, err)
+		}
+		root = tmpls[0]
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%s: template parse panic: %v", root.Name, p)
+		}
+	}()
+	tmpl, err := templates.PreProcessHtmlTemplate(root, funcs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", root.Name, err)
+	}
+	for _, block := range target.blocks {
+		if block != "" && tmpl.Lookup(block) == nil {
+			return fmt.Errorf("block %q not defined in %s", block, root.Name)
+		}
+	}
+	return nil
+}
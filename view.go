@@ -1,7 +1,13 @@
 package goapplib
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"sync"
 )
 
 // View is the interface that all pages/views must implement.
@@ -15,8 +21,9 @@ type View[AC any] interface {
 	Load(r *http.Request, w http.ResponseWriter, app *App[AC]) (err error, finished bool)
 }
 
-// Loader is the interface for mixins that can be loaded.
-// AC is the application context type.
+// Loader is the interface for loaders that can be chained with LoadAll.
+// AC is the application context type. Mixins (e.g., WithPagination[AC]) are
+// Loaders embedded in a view.
 type Loader[AC any] interface {
 	Load(r *http.Request, w http.ResponseWriter, app *App[AC]) (err error, finished bool)
 }
@@ -41,6 +48,146 @@ func LoadAll[AC any](r *http.Request, w http.ResponseWriter, app *App[AC], loade
 	return nil, false
 }
 
+// LoadParallel runs independent loaders concurrently and returns once all of
+// them are done. It has the same (error, finished) semantics as LoadAll: the
+// first loader in argument order that fails or finishes decides the outcome,
+// whatever order they complete in.
+//
+//   - Each loader gets the request with a shared, cancelable context. Once a
+//     loader has failed or finished and every loader before it has settled,
+//     the loaders after it are canceled. An earlier loader is never canceled
+//     by a later one, so it can still redirect.
+//   - Loaders write to their own buffered ResponseWriter. If the deciding
+//     loader returned finished=true, its response (status, headers, body) is
+//     written to w.
+//   - A panicking loader fails with an error instead of crashing the server.
+//   - Headers set by loaders that succeeded (cookies, HX-Trigger, ...) are
+//     copied to w in argument order.
+//
+// Loaders must only share state they synchronize themselves; typically each
+// one fills a different field of the view.
+func LoadParallel[AC any](r *http.Request, w http.ResponseWriter, app *App[AC], loaders ...Loader[AC]) (error, bool) {
+	ctx, cancel := context.WithCancelCause(r.Context())
+	defer cancel(nil)
+	pr := r.WithContext(ctx)
+
+	type result struct {
+		err      error
+		finished bool
+		rec      *responseRecorder
+	}
+	results := make([]result, len(loaders))
+
+	var (
+		mu      sync.Mutex
+		settled = make([]bool, len(loaders))
+		next    int // Loaders before next settled without deciding the outcome
+	)
+	settle := func(i int, res result) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i], settled[i] = res, true
+		for next < len(loaders) && settled[next] {
+			if res := results[next]; res.finished || res.err != nil {
+				cancel(errLoaderDecided)
+				next = len(loaders)
+				return
+			}
+			next++
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, loader := range loaders {
+		if loader == nil {
+			settle(i, result{})
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := newResponseRecorder()
+			// net/http only recovers the handler goroutine, not this one
+			defer func() {
+				if p := recover(); p != nil {
+					log.Printf("Loader panic for %s: %v", r.URL.Path, p)
+					settle(i, result{err: fmt.Errorf("loader panic: %v", p), rec: rec})
+				}
+			}()
+			err, finished := loader.Load(pr, rec, app)
+			settle(i, result{err: err, finished: finished, rec: rec})
+		}()
+	}
+	wg.Wait()
+
+	for _, res := range results {
+		if res.finished {
+			res.rec.replay(w)
+			return res.err, true
+		}
+		if res.err != nil {
+			return res.err, false
+		}
+	}
+
+	for _, res := range results {
+		if res.rec != nil {
+			res.rec.copyHeaders(w)
+		}
+	}
+	return nil, false
+}
+
+// errLoaderDecided is the cancellation cause once a loader has failed or
+// finished the response in LoadParallel.
+var errLoaderDecided = errors.New("an earlier loader failed or finished the response")
+
+// responseRecorder buffers a loader's response in LoadParallel.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: http.Header{}}
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.body.Write(b)
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+// copyHeaders adds the recorded headers to w.
+func (rec *responseRecorder) copyHeaders(w http.ResponseWriter) {
+	for key, values := range rec.header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+}
+
+// replay writes the recorded response to w.
+func (rec *responseRecorder) replay(w http.ResponseWriter) {
+	rec.copyHeaders(w)
+	if rec.status != 0 {
+		w.WriteHeader(rec.status)
+	}
+	rec.body.WriteTo(w)
+}
+
 // PageGroup is the interface for a group of related pages.
 // Implement this to define a set of routes under a common prefix.
 type PageGroup[AC any] interface {
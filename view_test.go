@@ -0,0 +1,92 @@
+package goapplib
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLoadParallelEarlierRedirectWins checks that a failing loader does not
+// cancel an earlier loader that is still working towards a redirect.
+func TestLoadParallelEarlierRedirectWins(t *testing.T) {
+	app := &App[struct{}]{}
+	redirecting := LoaderFunc[struct{}](func(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+		select {
+		case <-r.Context().Done():
+			return r.Context().Err(), false
+		case <-time.After(50 * time.Millisecond):
+		}
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, true
+	})
+	failing := LoaderFunc[struct{}](func(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+		return errors.New("backend down"), false
+	})
+
+	w := httptest.NewRecorder()
+	err, finished := LoadParallel(httptest.NewRequest("GET", "/", nil), w, app, redirecting, failing)
+	if err != nil || !finished {
+		t.Fatalf("got (%v, %v), want the redirect to finish the response", err, finished)
+	}
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login" {
+		t.Fatalf("got %d to %q, want 303 to /login", w.Code, w.Header().Get("Location"))
+	}
+}
+
+// TestLoadParallelEarlierErrorWins checks that an earlier error wins over a
+// later loader that finishes first, as with LoadAll, and that the later
+// loaders are canceled once it is decided.
+func TestLoadParallelEarlierErrorWins(t *testing.T) {
+	app := &App[struct{}]{}
+	slowFailing := LoaderFunc[struct{}](func(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+		time.Sleep(20 * time.Millisecond)
+		return errors.New("not found"), false
+	})
+	redirecting := LoaderFunc[struct{}](func(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+		http.Redirect(w, r, "/elsewhere", http.StatusSeeOther)
+		return nil, true
+	})
+	canceled := make(chan bool, 1)
+	waiting := LoaderFunc[struct{}](func(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+		select {
+		case <-r.Context().Done():
+			canceled <- true
+			return r.Context().Err(), false
+		case <-time.After(time.Second):
+			canceled <- false
+			return nil, false
+		}
+	})
+
+	w := httptest.NewRecorder()
+	err, finished := LoadParallel(httptest.NewRequest("GET", "/", nil), w, app, slowFailing, redirecting, waiting)
+	if finished || err == nil || err.Error() != "not found" {
+		t.Fatalf("got (%v, %v), want the first loader's error", err, finished)
+	}
+	if w.Header().Get("Location") != "" {
+		t.Fatalf("later redirect was written: %q", w.Header().Get("Location"))
+	}
+	if !<-canceled {
+		t.Fatal("later loader was not canceled")
+	}
+}
+
+// TestLoadParallelRecoversPanics checks that a panicking loader fails the
+// load instead of crashing the process.
+func TestLoadParallelRecoversPanics(t *testing.T) {
+	app := &App[struct{}]{}
+	ok := LoaderFunc[struct{}](func(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+		return nil, false
+	})
+	panicking := LoaderFunc[struct{}](func(r *http.Request, w http.ResponseWriter, app *App[struct{}]) (error, bool) {
+		panic("boom")
+	})
+
+	err, finished := LoadParallel(httptest.NewRequest("GET", "/", nil), httptest.NewRecorder(), app, ok, panicking)
+	if finished || err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("got (%v, %v), want the panic as an error", err, finished)
+	}
+}
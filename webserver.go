@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/felixge/httpsnoop"
 )
@@ -16,23 +17,57 @@ type WebAppServer struct {
 	Address       string
 	GrpcAddress   string
 	AllowLocalDev bool
+
+	// App, if set, gets local development features when AllowLocalDev is
+	// on: DevMode and hot-reloading of the templates in TemplatePaths (see
+	// App.EnableLocalDev). Without TemplatePaths nothing is reloaded, and a
+	// warning is logged.
+	App           LocalDevApp
+	TemplatePaths []string
+
+	// Routes, if set, is listed by a routes page (see RoutesHandler) served at
+	// RoutesPath when AllowLocalDev is on. RoutesPath defaults to "/_routes".
+	Routes     RouteLister
+	RoutesPath string
+
+	// Timeouts passed to http.Server. Zero means no timeout.
+	// Per-view load deadlines are set with WithTimeout.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+}
+
+// LocalDevApp is implemented by App, whatever its AppContext.
+type LocalDevApp interface {
+	EnableLocalDev(ctx context.Context, paths ...string) *TemplateReloader
 }
 
 // StartWithHandler starts the HTTP server with the given handler.
 func (s *WebAppServer) StartWithHandler(ctx context.Context, handler http.Handler, srvErr chan error, stopChan chan bool) error {
 	if s.AllowLocalDev {
 		PrintStartupMessage(s.Address)
+		if s.App != nil {
+			s.App.EnableLocalDev(ctx, s.TemplatePaths...)
+		}
 	} else {
 		log.Println("Starting http web server on: ", s.Address)
 	}
 	handler = withLogger(handler)
 	if s.AllowLocalDev {
 		handler = CORS(handler)
+		if s.Routes != nil {
+			handler = s.withRoutesPage(handler)
+		}
 	}
 	server := &http.Server{
-		Addr:        s.Address,
-		BaseContext: func(_ net.Listener) context.Context { return ctx },
-		Handler:     handler,
+		Addr:              s.Address,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+		Handler:           handler,
+		ReadHeaderTimeout: s.ReadHeaderTimeout,
+		ReadTimeout:       s.ReadTimeout,
+		WriteTimeout:      s.WriteTimeout,
+		IdleTimeout:       s.IdleTimeout,
 	}
 
 	go func() {
@@ -46,6 +81,23 @@ func (s *WebAppServer) StartWithHandler(ctx context.Context, handler http.Handle
 	return nil
 }
 
+// withRoutesPage serves the routes page at RoutesPath and everything else with next.
+func (s *WebAppServer) withRoutesPage(next http.Handler) http.Handler {
+	path := s.RoutesPath
+	if path == "" {
+		path = "/_routes"
+	}
+	page := RoutesHandler(s.Routes)
+	log.Printf("Serving routes at %s", path)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == path {
+			page.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func withLogger(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		m := httpsnoop.CaptureMetrics(handler, writer, request)